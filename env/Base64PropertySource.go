@@ -5,11 +5,11 @@ import (
 	"strings"
 
 	"github.com/go-errr/go/err"
-	"github.com/go-jang/go/util/optional"
 )
 
 // Custom property source as an additional logic for properties processing, like property=base64:dGVzdAo=
 type Base64PropertySource struct {
+	environment *Environment
 }
 
 func NewBase64PropertySource() *Base64PropertySource {
@@ -21,7 +21,7 @@ func (this *Base64PropertySource) Name() string {
 }
 
 func (this *Base64PropertySource) HasProperty(key string) bool {
-	for _, source := range environment.PropertySources() {
+	for _, source := range this.environment.PropertySources() {
 		if source.Properties() != nil && source.HasProperty(key) {
 			return strings.HasPrefix(source.Property(key), "base64:")
 		}
@@ -30,16 +30,25 @@ func (this *Base64PropertySource) HasProperty(key string) bool {
 }
 
 func (this *Base64PropertySource) Property(key string) string {
-	for _, source := range environment.PropertySources() {
+	for _, source := range this.environment.PropertySources() {
 		if source.Properties() != nil && source.HasProperty(key) {
 			value := source.Property(key)[7:]
-			return strings.TrimRight(string(optional.OfCommaErr(base64.StdEncoding.DecodeString(value)).
-				OrElsePanic("Cannot decode %s=%s", key, value)), "\n\r")
+			decoded, e := base64.StdEncoding.DecodeString(value)
+			if e != nil {
+				conversionError := NewConversionError(value, nil, e)
+				conversionError.Key, conversionError.Source = key, source.Name()
+				panic(conversionError)
+			}
+			return strings.TrimRight(string(decoded), "\n\r")
 		}
 	}
 	panic(err.NewIllegalArgumentException("No value present for " + key))
 }
 
+func (this *Base64PropertySource) SetEnvironment(environment *Environment) {
+	this.environment = environment
+}
+
 func (this *Base64PropertySource) Properties() map[string]string {
 	return nil
 }
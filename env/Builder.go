@@ -0,0 +1,138 @@
+package env
+
+import (
+	"io/fs"
+	"log/slog"
+	"os"
+)
+
+// Builder of independent Environment instances, not tied to the process-wide env.Instance().
+// Useful for tests and multi-tenant processes holding several environments at once.
+//
+//	environment := env.NewBuilder().
+//		Args("--server.port=9000").
+//		Environ("PROFILES_ACTIVE=dev").
+//		ConfigName("myapp").
+//		Locations("testdata/").
+//		Build()
+//
+// Options set on the builder take precedence over the equivalent command line arguments and environment variables.
+type Builder struct {
+	args                []string
+	environ             []string
+	workingDir          string
+	configName          string
+	locations           []string
+	additionalLocations []string
+	profiles            []string
+	defaultProfile      string
+	envPrefix           string
+	keyTags             []string
+	fileSystems         map[string]fs.FS
+	logger              *slog.Logger
+}
+
+// New builder initialized with process arguments and environment variables, the same env.Instance() uses
+func NewBuilder() *Builder {
+	return &Builder{
+		args:        os.Args[1:],
+		environ:     os.Environ(),
+		fileSystems: make(map[string]fs.FS),
+		logger:      slog.Default()}
+}
+
+// Command line arguments, without the program name, like --server.port=9000
+func (this *Builder) Args(args ...string) *Builder {
+	this.args = args
+	return this
+}
+
+// Environment variables in KEY=value form, like PROFILES_ACTIVE=dev
+func (this *Builder) Environ(environ ...string) *Builder {
+	this.environ = environ
+	return this
+}
+
+// Directory relative config locations are resolved against, current directory by default
+func (this *Builder) WorkingDir(dir string) *Builder {
+	this.workingDir = dir
+	return this
+}
+
+// Same as config.name
+func (this *Builder) ConfigName(name string) *Builder {
+	this.configName = name
+	return this
+}
+
+// Same as config.location
+func (this *Builder) Locations(locations ...string) *Builder {
+	this.locations = locations
+	return this
+}
+
+// Same as config.additional-location
+func (this *Builder) AdditionalLocations(locations ...string) *Builder {
+	this.additionalLocations = locations
+	return this
+}
+
+// Same as profiles.active, last wins
+func (this *Builder) Profiles(profiles ...string) *Builder {
+	this.profiles = profiles
+	return this
+}
+
+// Same as profiles.default
+func (this *Builder) DefaultProfile(profile string) *Builder {
+	this.defaultProfile = profile
+	return this
+}
+
+// Same as config.env-prefix
+func (this *Builder) EnvPrefix(prefix string) *Builder {
+	this.envPrefix = prefix
+	return this
+}
+
+// Same as config.key-tags, struct tags naming property keys of fields after the config tag, like json or yaml
+func (this *Builder) KeyTags(tags ...string) *Builder {
+	this.keyTags = tags
+	return this
+}
+
+// Registers file system, like embed.FS, to load config locations and imports prefixed with the given name from.
+// Imports declared in a file of the file system are resolved relative to that file within the same file system.
+//
+//	//go:embed config
+//	var defaults embed.FS
+//
+//	env.NewBuilder().FS("embed", defaults).Locations("embed:config/", "./", "./config/").Build()
+func (this *Builder) FS(name string, fsys fs.FS) *Builder {
+	this.fileSystems[name] = fsys
+	return this
+}
+
+// Logger to report locations probed, files loaded, imports followed, profiles activated and property sources registered,
+// slog.Default() by default. Use slog.New(slog.DiscardHandler) to turn logging off.
+func (this *Builder) Logger(logger *slog.Logger) *Builder {
+	this.logger = logger
+	return this
+}
+
+func (this *Builder) Build() *Environment {
+	environment := Environment{
+		propertySources: make([]PropertySource, 0),
+		exprProcessor:   ExprProcessorOf(true),
+		fileSystems:     this.fileSystems,
+		logger:          this.logger}
+	environment.exprProcessor.SetEnvironment(&environment)
+
+	environment.loadEnvironmentVariables(this.environ)
+	environment.loadApplicationParameters(this.args)
+	environment.loadApplicationConfiguration(this)
+	environment.WithPropertySource(NewRandomValuePropertySource())
+	environment.WithPropertySource(NewBase64PropertySource())
+	environment.WithPropertySource(NewCachedPropertySource())
+	return &environment
+}
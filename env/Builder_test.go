@@ -0,0 +1,124 @@
+package env_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-external-config/go/env"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Builder_Build(t *testing.T) {
+	t.Run("should build independent environments", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "myapp.yaml"), []byte("name: base\nsecret: base64:c2VjcmV0\nserver:\n  port: 8080\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "myapp-dev.yaml"), []byte("name: dev\n"), 0644))
+
+		dev := env.NewBuilder().
+			Args().
+			Environ("PROFILES_ACTIVE=dev").
+			WorkingDir(dir).
+			ConfigName("myapp").
+			Locations("./").
+			Build()
+		prod := env.NewBuilder().
+			Args("--name=cli").
+			Environ().
+			ConfigName("myapp").
+			Locations(dir + "/").
+			Profiles("prod").
+			Build()
+
+		require.Equal(t, []string{"default", "dev"}, dev.ActiveProfiles())
+		require.Equal(t, "dev", dev.Property("name"))
+		require.Equal(t, "secret", dev.Property("secret"))
+		require.Equal(t, []string{"default", "prod"}, prod.ActiveProfiles())
+		require.Equal(t, "cli", prod.Property("name"))
+		require.Equal(t, "secret", env.ValueFrom[string](prod, "${secret}"))
+		require.True(t, dev.MatchesProfiles("dev & !prod"))
+		require.False(t, prod.MatchesProfiles("dev"))
+
+		var server struct {
+			Port int
+		}
+		env.ConfigurationPropertiesFrom(dev, "server", &server)
+		require.Equal(t, 8080, server.Port)
+	})
+}
+
+func Test_Builder_EnvPrefix(t *testing.T) {
+	t.Run("should bind only prefixed environment variables", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.properties"), []byte("port=8080\nuser=app\nhome=${HOME}\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application-dev.properties"), []byte("profile=dev\n"), 0644))
+
+		environment := env.NewBuilder().
+			Args().
+			Environ("PORT=3000", "USER=root", "HOME=/home/app", "MYAPP_USER=svc", "MYAPP_PROFILES_ACTIVE=dev", "PROFILES_ACTIVE=prod").
+			Locations(dir + "/").
+			EnvPrefix("myapp").
+			Build()
+
+		require.Equal(t, "8080", environment.Property("port"))
+		require.Equal(t, "svc", environment.Property("user"))
+		require.Equal(t, "/home/app", environment.Property("home"))
+		require.Equal(t, "3000", environment.Property("PORT"))
+		require.Equal(t, []string{"default", "dev"}, environment.ActiveProfiles())
+		require.Equal(t, "Environment variables [MYAPP_USER]", environment.Origin("user").Value().String())
+	})
+
+	t.Run("should read prefix from CONFIG_ENVPREFIX", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args().
+			Environ("CONFIG_ENVPREFIX=MYAPP_", "PORT=3000", "MYAPP_SERVER_PORT=9000").
+			Locations().
+			Build()
+
+		_, e := environment.PropertyE("port")
+		require.Error(t, e)
+		require.Equal(t, "9000", environment.Property("server.port"))
+	})
+}
+
+func Test_Builder_FS(t *testing.T) {
+	t.Run("should overlay embedded configuration with files on disk", func(t *testing.T) {
+		embedded := fstest.MapFS{
+			"config/application.yaml":     {Data: []byte("name: embedded\nport: 8080\nconfig.import: db/db.properties\n")},
+			"config/application-dev.yaml": {Data: []byte("port: 8081\n")},
+			"config/db/db.properties":     {Data: []byte("db.url=embedded\n")},
+		}
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.yaml"), []byte("name: disk\n"), 0644))
+
+		environment := env.NewBuilder().
+			Args().
+			Environ().
+			FS("embed", embedded).
+			Locations("embed:config/", dir+"/").
+			Profiles("dev").
+			Build()
+
+		require.Equal(t, "disk", environment.Property("name"))
+		require.Equal(t, "8081", environment.Property("port"))
+		require.Equal(t, "embedded", environment.Property("db.url"))
+	})
+
+	t.Run("should resolve imports leaving the directory within the file system", func(t *testing.T) {
+		embedded := fstest.MapFS{
+			"config/application.yaml": {Data: []byte("config.import: ../shared.properties,optional:../missing.properties\n")},
+			"shared.properties":       {Data: []byte("shared=embedded\n")},
+		}
+
+		environment := env.NewBuilder().
+			Args().
+			Environ().
+			FS("embed", embedded).
+			Locations("embed:config/").
+			Build()
+
+		require.Equal(t, "embedded", environment.Property("shared"))
+		require.Equal(t, []string{"embed:missing.properties"}, environment.SkippedLocations())
+	})
+}
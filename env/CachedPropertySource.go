@@ -39,6 +39,7 @@ const CACHED_VALUE_PREFIX = "cached:"
 // This is useful for values that are expected to be unique, such as request,
 // message, or task identifiers.
 type CachedPropertySource struct {
+	environment      *Environment
 	cachedProperties *concurrent.HashMap[string, string]
 }
 
@@ -55,7 +56,7 @@ func (this *CachedPropertySource) HasProperty(key string) bool {
 	if this.cachedProperties.ContainsKey(key) {
 		return true
 	}
-	for _, source := range environment.PropertySources() {
+	for _, source := range this.environment.PropertySources() {
 		if source.Properties() != nil && source.HasProperty(key) {
 			return strings.HasPrefix(source.Property(key), CACHED_VALUE_PREFIX)
 		}
@@ -67,16 +68,20 @@ func (this *CachedPropertySource) Property(key string) string {
 	if this.cachedProperties.ContainsKey(key) {
 		return this.cachedProperties.Get(key)
 	}
-	for _, source := range environment.PropertySources() {
+	for _, source := range this.environment.PropertySources() {
 		if source.Properties() != nil && source.HasProperty(key) {
 			value := source.Property(key)[len(CACHED_VALUE_PREFIX):]
-			resolved := environment.ResolveRequiredPlaceholders(value)
+			resolved := this.environment.ResolveRequiredPlaceholders(value)
 			return this.cachedProperties.PutIfAbsent(key, fmt.Sprint(resolved))
 		}
 	}
 	panic(err.NewIllegalArgumentException("No value present for " + key))
 }
 
+func (this *CachedPropertySource) SetEnvironment(environment *Environment) {
+	this.environment = environment
+}
+
 func (this *CachedPropertySource) Properties() map[string]string {
 	return nil
 }
@@ -0,0 +1,18 @@
+package env
+
+import "github.com/go-jang/go/util/optional"
+
+// ChangeEvent describes properties changed by reloading a configuration file, see Environment.Watch.
+// Changes are limited to the keys under the prefix the listener subscribed to and sorted by key.
+type ChangeEvent struct {
+	Source  string
+	Changes []PropertyChange
+}
+
+// Effective raw value of the property before and after the reload.
+// OldValue is empty if the property has been added, NewValue is empty if it has been removed.
+type PropertyChange struct {
+	Key      string
+	OldValue *optional.Optional[string]
+	NewValue *optional.Optional[string]
+}
@@ -0,0 +1,105 @@
+package env
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var negativeNumberPattern = regexp.MustCompile(`^-\d`)
+
+// Property source of command line arguments:
+//
+//	--server.port=9000 --server.host localhost -v --tag=a --tag=b file1 -- --file2
+//
+// becomes
+//
+//	server.port=9000
+//	server.host=localhost
+//	v=true
+//	tag=a,b
+//	tag[0]=a
+//	tag[1]=b
+//
+// with file1 and --file2 left as positional arguments.
+// Option without value takes the next argument unless it is an option itself, otherwise the value is true.
+// Environment parses the arguments again as property sources and flags are added, there --verbose input.txt takes
+// input.txt only if verbose is a setting of the environment, a property defined with a value other than true or false,
+// or a registered flag that is not boolean, see Environment.RegisterFlags. Otherwise input.txt stays positional.
+type CommandLinePropertySource struct {
+	MapPropertySource
+	args           []string
+	positionalArgs []string
+	overrides      map[string]string // set by flags, kept when parsed again
+}
+
+func NewCommandLinePropertySource(args []string) *CommandLinePropertySource {
+	commandLinePropertySource := CommandLinePropertySource{
+		MapPropertySource: *MapPropertySourceOf("Application parameters"),
+		args:              args,
+		overrides:         make(map[string]string)}
+	commandLinePropertySource.parse(func(string) bool { return true })
+	return &commandLinePropertySource
+}
+
+// option without value takes the next argument if takesValue tells so for its key, otherwise the value is true
+func (this *CommandLinePropertySource) parse(takesValue func(key string) bool) {
+	var keys, positionalArgs []string
+	values := make(map[string][]string)
+	for i := 0; i < len(this.args); i++ {
+		arg := this.args[i]
+		if arg == "--" {
+			positionalArgs = append(positionalArgs, this.args[i+1:]...)
+			break
+		}
+		if !isOption(arg) {
+			positionalArgs = append(positionalArgs, arg)
+			continue
+		}
+		key, value, found := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-"), "=")
+		if len(key) == 0 {
+			continue
+		}
+		if !found && i+1 < len(this.args) && this.args[i+1] != "--" && !isOption(this.args[i+1]) && takesValue(key) {
+			i++
+			value = this.args[i]
+		} else if !found {
+			value = "true"
+		}
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+		values[key] = append(values[key], value)
+	}
+	properties := make(map[string]string)
+	for _, key := range keys {
+		properties[key] = strings.Join(values[key], ",")
+		if len(values[key]) > 1 {
+			for i, value := range values[key] {
+				properties[key+"["+strconv.Itoa(i)+"]"] = value
+			}
+		}
+	}
+	this.SetProperties(properties)
+	for key, value := range this.overrides {
+		this.SetProperty(key, value)
+	}
+	this.positionalArgs = positionalArgs
+}
+
+// value of a flag set on parse, see Environment.RegisterFlags
+func (this *CommandLinePropertySource) override(key, value string) {
+	this.overrides[key] = value
+	this.SetProperty(key, value)
+}
+
+// Arguments that are not options, like file names, in the order given
+func (this *CommandLinePropertySource) PositionalArgs() []string {
+	return slices.Clone(this.positionalArgs)
+}
+
+// -v or --key, but not - standing for stdin or negative number
+func isOption(arg string) bool {
+	return strings.HasPrefix(arg, "-") && arg != "-" && !negativeNumberPattern.MatchString(arg)
+}
@@ -0,0 +1,69 @@
+package env_test
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/go-external-config/go/env"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CommandLinePropertySource_Resolve(t *testing.T) {
+	t.Run("should parse options and positional arguments", func(t *testing.T) {
+		source := env.NewCommandLinePropertySource([]string{
+			"--server.port=9000",
+			"--server.host", "localhost",
+			"-v",
+			"--offset", "-5",
+			"--tag=a", "input.txt", "--tag=b",
+			"--empty=",
+			"-",
+			"--", "--not-an-option", "-x"})
+
+		require.Equal(t, map[string]string{
+			"server.port": "9000",
+			"server.host": "localhost",
+			"v":           "true",
+			"offset":      "-5",
+			"tag":         "a,b",
+			"tag[0]":      "a",
+			"tag[1]":      "b",
+			"empty":       ""}, source.Properties())
+		require.Equal(t, []string{"input.txt", "-", "--not-an-option", "-x"}, source.PositionalArgs())
+	})
+
+	t.Run("should take precedence over files", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args("file1", "--name", "cli", "--debug").
+			Environ().
+			Build().
+			WithPropertySource(env.MapPropertySourceOfMap("file", map[string]string{"name": "file", "debug": "false"}))
+
+		require.Equal(t, "cli", environment.Property("name"))
+		require.True(t, env.ValueFrom[bool](environment, "${debug}"))
+		require.Equal(t, []string{"file1"}, environment.PositionalArgs())
+	})
+
+	t.Run("should take next argument only for options known to take a value", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args("--verbose", "input.txt", "--name", "cli", "--profiles.active", "dev", "--workers", "4", "--debug", "output.txt").
+			Environ().
+			Build().
+			WithPropertySource(env.MapPropertySourceOfMap("file", map[string]string{"name": "file", "debug": "false"}))
+
+		require.Equal(t, "true", environment.Property("verbose"))
+		require.Equal(t, "cli", environment.Property("name"))
+		require.Equal(t, []string{"default", "dev"}, environment.ActiveProfiles())
+		require.Equal(t, "true", environment.Property("debug"))
+		require.Equal(t, []string{"input.txt", "4", "output.txt"}, environment.PositionalArgs())
+
+		flags := flag.NewFlagSet("myapp", flag.ContinueOnError)
+		workers := flags.Int("workers", 1, "number of workers")
+		environment.RegisterFlags(flags)
+
+		require.Equal(t, "4", environment.Property("workers"))
+		require.Equal(t, []string{"input.txt", "output.txt"}, environment.PositionalArgs())
+		require.NoError(t, flags.Parse([]string{"-workers", "4"}))
+		require.Equal(t, 4, *workers)
+	})
+}
@@ -0,0 +1,59 @@
+package env
+
+import (
+	"io/fs"
+	pathpkg "path"
+	"strings"
+
+	"github.com/go-jang/go/util/optional"
+)
+
+// Property source of directory tree, like Kubernetes ConfigMap or Secret mounted as volume.
+// Every file is a property named after its path with nested directories becoming dotted keys, content with trailing newlines trimmed is the value:
+//
+//	/etc/secrets/db/password
+//
+// becomes
+//
+//	db.password=<content of the file>
+//
+// Hidden entries are skipped, so ..data and ..2024_01_01_00_00_00.000000000 directories Kubernetes uses for atomic updates
+// are read only through the symlinks pointing into them.
+type ConfigTreePropertySource struct {
+	MapPropertySource
+}
+
+func NewConfigTreePropertySource(name string, fsys fs.FS) *ConfigTreePropertySource {
+	configTreePropertySource := ConfigTreePropertySource{
+		MapPropertySource: *MapPropertySourceOf(name)}
+	properties := make(map[string]string)
+	walkConfigTree(name, fsys, ".", func(path string, info fs.FileInfo) {
+		key := strings.ReplaceAll(path, "/", ".")
+		content := optional.OfCommaErr(fs.ReadFile(fsys, path)).OrElsePanic("Cannot read from %s", pathpkg.Join(name, path))
+		properties[key] = strings.TrimRight(string(content), "\r\n")
+		configTreePropertySource.SetOrigin(key, Origin{Source: pathpkg.Join(name, path), Key: key})
+	})
+	configTreePropertySource.SetProperties(properties)
+	return &configTreePropertySource
+}
+
+// visits regular files of the tree, following symlinks and skipping hidden entries
+func walkConfigTree(name string, fsys fs.FS, dir string, visit func(path string, info fs.FileInfo)) {
+	entries := optional.OfCommaErr(fs.ReadDir(fsys, dir)).OrElsePanic("Cannot read config tree %s", pathpkg.Join(name, dir))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := pathpkg.Join(dir, entry.Name())
+		// symlinks are resolved, entry.Type() would report the link itself
+		info, e := fs.Stat(fsys, path)
+		if e != nil {
+			continue
+		}
+		if info.IsDir() {
+			walkConfigTree(name, fsys, path, visit)
+		} else if info.Mode().IsRegular() {
+			visit(path, info)
+		}
+	}
+}
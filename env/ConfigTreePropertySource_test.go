@@ -0,0 +1,102 @@
+package env_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-external-config/go/env"
+	"github.com/stretchr/testify/require"
+)
+
+// lays out files the way kubelet mounts ConfigMap and Secret volumes: dir/key -> ..data/key, ..data -> ..<version>
+func writeConfigTree(t *testing.T, dir, version string, files map[string]string) {
+	versionDir := filepath.Join(dir, ".."+version)
+	// every version is newer than the previous one even on file systems with coarse timestamps
+	modTime := time.Now().Add(time.Duration(len(version)) * time.Minute)
+	for name, content := range files {
+		path := filepath.Join(versionDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	data := filepath.Join(dir, "..data")
+	require.NoError(t, os.Symlink(".."+version, data+"_tmp"))
+	require.NoError(t, os.Rename(data+"_tmp", data))
+	for name := range files {
+		top, _, _ := strings.Cut(name, "/")
+		if _, e := os.Lstat(filepath.Join(dir, top)); e != nil {
+			require.NoError(t, os.Symlink(filepath.Join("..data", top), filepath.Join(dir, top)))
+		}
+	}
+}
+
+func Test_ConfigTreePropertySource_Resolve(t *testing.T) {
+	t.Run("should turn files into properties", func(t *testing.T) {
+		source := env.NewConfigTreePropertySource("secrets", fstest.MapFS{
+			"username":        {Data: []byte("admin\n")},
+			"db/password":     {Data: []byte("secret\r\n")},
+			"db/url":          {Data: []byte("jdbc:postgresql://db:5432/app")},
+			".hidden":         {Data: []byte("hidden")},
+			"..data/username": {Data: []byte("duplicate")},
+		})
+
+		require.Equal(t, map[string]string{
+			"username":    "admin",
+			"db.password": "secret",
+			"db.url":      "jdbc:postgresql://db:5432/app"}, source.Properties())
+		require.Equal(t, "secrets/db/password [db.password]", source.Origin("db.password").Value().String())
+	})
+
+	t.Run("should import kubernetes volume through ..data symlink", func(t *testing.T) {
+		dir := t.TempDir()
+		secrets := filepath.Join(dir, "secrets")
+		require.NoError(t, os.Mkdir(secrets, 0755))
+		writeConfigTree(t, secrets, "2024_01_01", map[string]string{
+			"username":    "admin\n",
+			"db/password": "secret\n"})
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.properties"), []byte("config.import=configtree:secrets/\nusername=default\n"), 0644))
+
+		environment := env.NewBuilder().
+			Args().
+			Environ().
+			Locations(dir + "/").
+			Build()
+
+		require.Equal(t, "admin", environment.Property("username"))
+		require.Equal(t, "secret", environment.Property("db.password"))
+		_, e := environment.PropertyE("..data.username")
+		require.Error(t, e)
+	})
+
+	t.Run("should reload when kubernetes swaps ..data", func(t *testing.T) {
+		dir := t.TempDir()
+		writeConfigTree(t, dir, "2024_01_01", map[string]string{"db/password": "secret1\n"})
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.properties"), []byte("config.import=configtree:"+filepath.ToSlash(dir)+"/\n"), 0644))
+		environment := env.NewBuilder().
+			Args().
+			Environ().
+			Locations(dir + "/").
+			Build()
+		events := make(chan env.ChangeEvent, 10)
+		environment.OnChange("db", func(event env.ChangeEvent) {
+			events <- event
+		})
+		stop := environment.Watch(10 * time.Millisecond)
+		defer stop()
+
+		writeConfigTree(t, dir, "2024_01_02_00", map[string]string{"db/password": "secret2\n"})
+
+		select {
+		case event := <-events:
+			require.Len(t, event.Changes, 1)
+			require.Equal(t, "secret2", event.Changes[0].NewValue.Value())
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "change event expected")
+		}
+		require.Equal(t, "secret2", environment.Property("db.password"))
+	})
+}
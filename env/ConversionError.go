@@ -0,0 +1,49 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-errr/go/err"
+)
+
+// ConversionError reports a property value that cannot be turned into the requested type,
+// including raw values a preprocessing property source (base64:, RSA:) fails to decode.
+//
+// Key and Source are empty when the value does not come from a single property,
+// for example env.Value[int]("#{1 + ${a}}").
+type ConversionError struct {
+	err.RuntimeException
+	Key    string
+	Source string
+	Value  any
+	Type   reflect.Type
+}
+
+func NewConversionError(value any, t reflect.Type, cause any) *ConversionError {
+	return &ConversionError{
+		RuntimeException: *err.NewRuntimeExceptionWith("", cause, err.StackTrace(1)),
+		Value:            value,
+		Type:             t}
+}
+
+func (this *ConversionError) Error() string {
+	var message strings.Builder
+	if this.Type != nil {
+		fmt.Fprintf(&message, "Cannot convert '%v' to %v", this.Value, this.Type)
+	} else {
+		fmt.Fprintf(&message, "Cannot decode '%v'", this.Value)
+	}
+	if len(this.Key) > 0 {
+		fmt.Fprintf(&message, " for %s", this.Key)
+	}
+	if len(this.Source) > 0 {
+		fmt.Fprintf(&message, " from %s", this.Source)
+	}
+	return message.String()
+}
+
+func (this *ConversionError) Format(s fmt.State, verb rune) {
+	this.DefaultFormat(s, verb, this)
+}
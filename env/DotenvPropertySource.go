@@ -0,0 +1,183 @@
+package env
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-errr/go/err"
+	"github.com/go-jang/go/util/optional"
+)
+
+var dotenvKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// Property source of .env file as used by Docker Compose and local development setups.
+//
+//	# comment
+//	export DB_HOST=localhost
+//	DB_URL="postgres://${DB_HOST}:${DB_PORT:-5432}/app" # double quoted values support escapes like \n
+//	DB_PASSWORD='literal $value'
+//	MULTILINE="first
+//	second"
+//
+// ${VAR}, ${VAR:-default}, ${VAR-default} and $VAR are interpolated in unquoted and double quoted values
+// from the keys defined above in the file, then from the variables given, typically environment variables.
+//
+// Keys are also matched in environment variable canonical form, so DB_URL satisfies ${db.url}.
+// Single quoted values are literal, the environment does not resolve placeholders and expressions in them.
+type DotenvPropertySource struct {
+	MapPropertySource
+	literals map[string]bool // keys of single quoted values
+}
+
+func NewDotenvPropertySource(name, content string, variables PropertySource) *DotenvPropertySource {
+	dotenvPropertySource := DotenvPropertySource{
+		MapPropertySource: *MapPropertySourceOf(name),
+		literals:          make(map[string]bool)}
+	dotenvPropertySource.parse(content, variables)
+	return &dotenvPropertySource
+}
+
+func (this *DotenvPropertySource) HasProperty(key string) bool {
+	return this.MapPropertySource.HasProperty(key) || this.MapPropertySource.HasProperty(envVarCanonicalForm(key))
+}
+
+func (this *DotenvPropertySource) Property(key string) string {
+	if this.MapPropertySource.HasProperty(key) {
+		return this.MapPropertySource.Property(key)
+	}
+	return this.MapPropertySource.Property(envVarCanonicalForm(key))
+}
+
+func (this *DotenvPropertySource) Origin(key string) *optional.Optional[Origin] {
+	return this.MapPropertySource.Origin(key).OrElseOptional(this.MapPropertySource.Origin(envVarCanonicalForm(key)))
+}
+
+// single quoted, so already resolved
+func (this *DotenvPropertySource) literal(key string) bool {
+	if this.MapPropertySource.HasProperty(key) {
+		return this.literals[key]
+	}
+	return this.literals[envVarCanonicalForm(key)]
+}
+
+func (this *DotenvPropertySource) parse(content string, variables PropertySource) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		lineNumber := i + 1
+		line = strings.TrimLeft(strings.TrimPrefix(line, "export "), " \t")
+		separator := strings.IndexByte(line, '=')
+		key := strings.TrimSpace(line[:max(separator, 0)])
+		if separator < 0 || !dotenvKeyPattern.MatchString(key) {
+			panic(err.NewRuntimeException(fmt.Sprintf("Cannot parse %s at line %d, KEY=value expected", this.name, lineNumber)))
+		}
+		rest := strings.TrimLeft(line[separator+1:], " \t")
+		column := strings.LastIndex(lines[i], rest) + 1
+		var value string
+		if len(rest) > 0 && (rest[0] == '\'' || rest[0] == '"') {
+			quote := rest[0]
+			quoted := rest[1:]
+			for closing(quoted, quote) < 0 && i+1 < len(lines) {
+				i++
+				quoted += "\n" + lines[i]
+			}
+			end := closing(quoted, quote)
+			if end < 0 {
+				panic(err.NewRuntimeException(fmt.Sprintf("Cannot parse %s at line %d, closing quote %c expected", this.name, lineNumber, quote)))
+			}
+			if quote == '"' {
+				value = this.expand(quoted[:end], true, variables)
+			} else {
+				value = quoted[:end]
+			}
+		} else {
+			if comment := strings.Index(rest, " #"); comment >= 0 {
+				rest = rest[:comment]
+			}
+			value = this.expand(strings.TrimSpace(rest), false, variables)
+		}
+		this.SetProperty(key, value)
+		this.literals[key] = len(rest) > 0 && rest[0] == '\''
+		this.SetOrigin(key, Origin{
+			Source: this.name,
+			Key:    key,
+			Line:   lineNumber,
+			Column: column})
+	}
+}
+
+// index of the closing quote, double quote can be escaped with backslash
+func closing(quoted string, quote byte) int {
+	for i := 0; i < len(quoted); i++ {
+		if quoted[i] == '\\' && quote == '"' {
+			i++
+		} else if quoted[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func (this *DotenvPropertySource) expand(value string, escapes bool, variables PropertySource) string {
+	var result strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if escapes && c == '\\' && i+1 < len(value) {
+			i++
+			switch value[i] {
+			case 'n':
+				result.WriteByte('\n')
+			case 'r':
+				result.WriteByte('\r')
+			case 't':
+				result.WriteByte('\t')
+			default:
+				result.WriteByte(value[i])
+			}
+		} else if c == '$' && i+1 < len(value) && value[i+1] == '{' {
+			end := strings.IndexByte(value[i:], '}')
+			if end < 0 {
+				result.WriteString(value[i:])
+				break
+			}
+			result.WriteString(this.variable(value[i+2:i+end], variables))
+			i += end
+		} else if c == '$' && i+1 < len(value) && (value[i+1] == '_' || isLetter(value[i+1])) {
+			end := i + 1
+			for end < len(value) && (value[end] == '_' || isLetter(value[end]) || value[end] >= '0' && value[end] <= '9') {
+				end++
+			}
+			result.WriteString(this.variable(value[i+1:end], variables))
+			i = end - 1
+		} else {
+			result.WriteByte(c)
+		}
+	}
+	return result.String()
+}
+
+// NAME, NAME:-default if unset or empty, NAME-default if unset
+func (this *DotenvPropertySource) variable(expression string, variables PropertySource) string {
+	name, defaultValue, unsetOnly := expression, "", false
+	if i := strings.Index(expression, ":-"); i >= 0 {
+		name, defaultValue = expression[:i], expression[i+2:]
+	} else if i := strings.IndexByte(expression, '-'); i >= 0 {
+		name, defaultValue, unsetOnly = expression[:i], expression[i+1:], true
+	}
+	value, set := this.properties[name]
+	if !set && variables != nil && variables.HasProperty(name) {
+		value, set = variables.Property(name), true
+	}
+	if !set || !unsetOnly && len(value) == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
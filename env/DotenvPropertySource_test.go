@@ -0,0 +1,106 @@
+package env_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-external-config/go/env"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DotenvPropertySource_Parse(t *testing.T) {
+	t.Run("should parse dotenv syntax", func(t *testing.T) {
+		variables := env.MapPropertySourceOfMap("Environment variables", map[string]string{
+			"HOME":  "/home/app",
+			"EMPTY": ""})
+		source := env.NewDotenvPropertySource(".env", `# comment
+export DB_HOST=localhost
+DB_PORT = 5432 # inline comment
+DB_URL="postgres://${DB_HOST}:$DB_PORT/app\tdb"
+LITERAL='${DB_HOST} \n'
+MULTILINE="first
+second"
+HOME_DIR=${HOME}/data
+DEFAULT=${MISSING:-fallback}
+EMPTY_DEFAULT=${EMPTY:-fallback}
+UNSET_DEFAULT=${EMPTY-fallback}
+QUOTE="say \"hi\""
+`, variables)
+
+		require.Equal(t, "localhost", source.Property("DB_HOST"))
+		require.Equal(t, "5432", source.Property("DB_PORT"))
+		require.Equal(t, "postgres://localhost:5432/app\tdb", source.Property("DB_URL"))
+		require.Equal(t, `${DB_HOST} \n`, source.Property("LITERAL"))
+		require.Equal(t, "first\nsecond", source.Property("MULTILINE"))
+		require.Equal(t, "/home/app/data", source.Property("HOME_DIR"))
+		require.Equal(t, "fallback", source.Property("DEFAULT"))
+		require.Equal(t, "fallback", source.Property("EMPTY_DEFAULT"))
+		require.Equal(t, "", source.Property("UNSET_DEFAULT"))
+		require.Equal(t, `say "hi"`, source.Property("QUOTE"))
+		require.Equal(t, ".env:4:8", source.Origin("db.url").Value().String())
+	})
+
+	t.Run("should keep single quoted values literal in the environment", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("PASSWORD='lit${X}'\nHASH='#{1+1}'\nSUM=#{1+1}\n"), 0644))
+
+		environment := env.NewBuilder().
+			Args().
+			Environ().
+			Locations(dir + "/.env").
+			Build()
+
+		require.Equal(t, "lit${X}", environment.Property("password"))
+		require.Equal(t, "#{1+1}", environment.Property("hash"))
+		require.Equal(t, "2", environment.Property("sum"))
+		require.Equal(t, "lit${X}", env.ValueFrom[string](environment, "${PASSWORD}"))
+
+		var secrets struct {
+			Password string `value:"${password}"`
+			Hash     string
+		}
+		env.BindPropertiesFrom(environment, &secrets)
+		env.ConfigurationPropertiesFrom(environment, "", &secrets)
+		require.Equal(t, "lit${X}", secrets.Password)
+		require.Equal(t, "#{1+1}", secrets.Hash)
+	})
+
+	t.Run("should satisfy canonical keys", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("DB_URL=jdbc\nMAIN_LOGSTARTUPINFO=true\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "local"), []byte("DB_USER=sa\n"), 0644))
+
+		environment := env.NewBuilder().
+			Args().
+			Environ().
+			Locations(dir+"/.env", dir+"/local[.env]").
+			Build()
+
+		require.Equal(t, "jdbc", environment.Property("db.url"))
+		require.Equal(t, "true", environment.Property("main.log-startup-info"))
+		require.Equal(t, "sa", environment.Property("db.user"))
+	})
+
+	t.Run("should bind lists and maps of environment variable keys", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("DB_HOSTS_0_=a,1\nDB_HOSTS_1_=b\nDB_LABELS_TEAM=core\nDB_POOL_SIZE=5\n"), 0644))
+
+		environment := env.NewBuilder().
+			Args().
+			Environ().
+			Locations(dir + "/.env").
+			Build()
+
+		var db struct {
+			Hosts  []string
+			Labels map[string]string
+			Pool   *struct{ Size int }
+		}
+		env.ConfigurationPropertiesFrom(environment, "db", &db)
+
+		require.Equal(t, []string{"a,1", "b"}, db.Hosts)
+		require.Equal(t, map[string]string{"team": "core"}, db.Labels)
+		require.Equal(t, 5, db.Pool.Size)
+	})
+}
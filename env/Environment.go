@@ -1,18 +1,28 @@
 package env
 
 import (
+	"flag"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
+	pathpkg "path"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-errr/go/err"
 	"github.com/go-external-config/go/files"
 	"github.com/go-external-config/go/str"
 	"github.com/go-jang/go/lang"
+	"github.com/go-jang/go/util"
 	"github.com/go-jang/go/util/collections"
 	"github.com/go-jang/go/util/concurrent"
 	"github.com/go-jang/go/util/objects"
@@ -21,6 +31,15 @@ import (
 )
 
 var locationPattern = regexp.MustCompile(regex.NewPatternBuilder().Next(`{location:.+}\[{fantomExt:\.[\w]+}\]`).Build())
+
+// config.location=optional:/etc/myapp/ may be missing, otherwise locations and imports must exist
+const optionalPrefix = "optional:"
+
+// config.import=configtree:/etc/secrets/ loads the directory as ConfigTreePropertySource
+const configTreePrefix = "configtree:"
+
+// looked up in directory locations, last wins
+var configFileExtensions = []string{".json", ".toml", ".yml", ".yaml", ".properties"}
 var envVarCanonicalFormTranslationRule = map[rune]rune{
 	'.': '_',
 	'[': '_',
@@ -33,58 +52,267 @@ var environmentMu sync.Mutex
 
 type Environment struct {
 	activeProfiles        []string
-	paramsPropertySource  *MapPropertySource
+	defaultProfile        string   // stands for files without profile suffix, always active
+	activatedProfiles     []string // profiles.active
+	includedProfiles      []string // profiles.include
+	profileGroups         map[string][]string
+	loadedProfiles        int // leading active profiles the current location is loaded for
+	matchedProfiles       map[string]bool
+	paramsPropertySource  *CommandLinePropertySource
 	environPropertySource *MapPropertySource
+	envPrefix             string   // like MYAPP_, environment variables take part in relaxed lookup only if prefixed
+	keyTags               []string // struct tags naming property keys of fields, config and config.key-tags
 	propertySources       []PropertySource
 	exprProcessor         *ExprProcessor
+	fileSystems           map[string]fs.FS
+	loadedFiles           []*loadedFile
+	loadingFiles          []*loadedFile // import chain of the file being loaded
+	skippedLocations      []string
+	skippedDocuments      []skippedDocument // config.activate.on-profile not matching profiles active so far
+	changeListeners       []*changeListener
+	flagSets              []*flag.FlagSet // registered with RegisterFlags
+	logger                *slog.Logger
+	mu                    sync.RWMutex // guards propertySources, replaced as a whole on change, and changeListeners
+	reloadMu              sync.Mutex
+}
+
+type loadedFile struct {
+	path      string
+	canonical string // absolute with symlinks resolved, identifies the file however it is referred to
+	ext       string
+	sources   []PropertySource // active documents
+	stamp     fileStamp
+}
+
+// document of a loaded file, placed after the property source it would have followed once its profiles are active
+type skippedDocument struct {
+	file      *loadedFile
+	source    PropertySource
+	after     PropertySource // nil for the first one
+	fileIndex int            // position among the documents of the file
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+type changeListener struct {
+	prefix   string
+	listener func(ChangeEvent)
 }
 
 func Instance() *Environment {
 	if environment == nil {
 		concurrent.Synchronized(&environmentMu, func() {
 			if environment == nil {
-				environment = newEnvironment("")
+				environment = NewBuilder().Build()
 			}
 		})
 	}
 	return environment
 }
 
-func newEnvironment(activeProfiles string) *Environment {
-	environment := Environment{
-		activeProfiles:  []string{"default"},
-		propertySources: make([]PropertySource, 0),
-		exprProcessor:   ExprProcessorOf(true)}
-
-	environment.loadEnvironmentVariables()
-	environment.loadApplicationParameters()
-	environment.loadApplicationConfiguration(activeProfiles)
-	environment.WithPropertySource(NewRandomValuePropertySource())
-	environment.WithPropertySource(NewBase64PropertySource())
-	environment.WithPropertySource(NewCachedPropertySource())
-	return &environment
+func (this *Environment) Property(key string) string {
+	return fmt.Sprint(this.resolveProperty(key))
 }
 
-func (this *Environment) Property(key string) string {
-	return fmt.Sprint(this.ResolveRequiredPlaceholders(this.lookupRawProperty(key).
-		OrElsePanic("No value present for %s", key)))
+// Same as Property, but returns *PropertyNotFoundError, *ConversionError or *ExpressionError instead of panicking.
+func (this *Environment) PropertyE(key string) (value string, e error) {
+	defer err.Catch(func(cause any) {
+		e = asError(cause)
+	})
+	return this.Property(key), nil
 }
 
 func (this *Environment) lookupRawProperty(key string) *optional.Optional[string] {
+	if source, sourceKey := this.lookupPropertySource(key); source != nil {
+		return optional.OfValue(source.Property(sourceKey))
+	}
+	return this.lookupRawList(key)
+}
+
+// my.servers[0] and my.servers[1] joined with commas for my.servers, so lists are available as a whole.
+// Empty for list of structs, my.servers[0].host
+func (this *Environment) lookupRawList(key string) *optional.Optional[string] {
+	indexes := this.propertyIndexes(key)
+	if len(indexes) == 0 {
+		return optional.OfEmpty[string]()
+	}
+	items := make([]string, indexes[len(indexes)-1]+1)
+	for _, i := range indexes {
+		source, sourceKey := this.lookupPropertySource(fmt.Sprintf("%s[%d]", key, i))
+		if source == nil {
+			return optional.OfEmpty[string]()
+		}
+		items[i] = source.Property(sourceKey)
+	}
+	return optional.OfValue(strings.Join(items, ","))
+}
+
+// returns the source that defines the key along with the key as it is known to that source
+func (this *Environment) lookupPropertySource(key string) (PropertySource, string) {
 	if this.paramsPropertySource.HasProperty(key) {
-		return optional.OfValue(this.paramsPropertySource.Property(key))
+		return this.paramsPropertySource, this.paramsPropertySource.relaxedKey(key)
 	} else if this.environPropertySource.HasProperty(key) {
-		return optional.OfValue(this.environPropertySource.Property(key))
-	} else if envCanonical := this.envVarCanonicalForm(key); this.environPropertySource.HasProperty(envCanonical) {
-		return optional.OfValue(this.environPropertySource.Property(envCanonical))
+		return this.environPropertySource, key
+	} else if envKey, ok := this.envKey(key); ok {
+		return this.environPropertySource, envKey
 	} else {
-		for i := len(this.propertySources) - 1; i >= 0; i-- {
-			if this.propertySources[i].HasProperty(key) {
-				return optional.OfValue(this.propertySources[i].Property(key))
+		propertySources := this.sources()
+		for i := len(propertySources) - 1; i >= 0; i-- {
+			if propertySources[i].HasProperty(key) {
+				if relaxed, ok := propertySources[i].(interface{ relaxedKey(string) string }); ok {
+					return propertySources[i], relaxed.relaxedKey(key)
+				}
+				return propertySources[i], key
 			}
 		}
 	}
-	return optional.OfEmpty[string]()
+	return nil, key
+}
+
+// environment variable of the key with the prefix of the environment, MY_SERVICE_0_OTHER for my.service[0].other,
+// MY_SERVERS_0_ or MY_SERVERS_0 for my.servers[0], relaxed otherwise
+func (this *Environment) envKey(key string) (string, bool) {
+	envCanonical := this.envPrefix + envVarCanonicalForm(key)
+	if this.environPropertySource.HasProperty(envCanonical) {
+		return envCanonical, true
+	}
+	if envCanonical = this.envPrefix + envVarPrefixForm(key); strings.HasSuffix(key, "]") && this.environPropertySource.HasProperty(envCanonical) {
+		return envCanonical, true
+	}
+	return this.relaxedEnvKey(key)
+}
+
+// environment variable matching the key in canonical form, so POOL_MAX_IDLE_CONNS is found for pool.maxIdleConns
+// along with POOL_MAXIDLECONNS. Not for list elements, MY_SERVERS_1_0_ and MY_SERVERS_10_ would be the same
+func (this *Environment) relaxedEnvKey(key string) (string, bool) {
+	if strings.ContainsAny(key, "[]") {
+		return "", false
+	}
+	envKey, ok := this.environPropertySource.relaxedKeys[canonicalForm(this.envPrefix+envVarCanonicalForm(key))]
+	return envKey, ok && strings.HasPrefix(envKey, this.envPrefix)
+}
+
+// Where the raw value of the property is defined, like ./config/application.yaml:12:7 or Environment variables [DB_URL].
+// Useful to tell which of the loaded files, environment variables or command line arguments supplied a value.
+func (this *Environment) Origin(key string) *optional.Optional[Origin] {
+	source, sourceKey := this.lookupPropertySource(key)
+	if source == nil {
+		return optional.OfEmpty[Origin]()
+	}
+	if source.Properties() == nil {
+		// preprocessors like Base64PropertySource transform the value of the source defining the key
+		for _, delegate := range this.PropertySources() {
+			if delegate.Properties() != nil && delegate.HasProperty(sourceKey) {
+				source = delegate
+				break
+			}
+		}
+	}
+	if lookup, ok := source.(OriginLookup); ok {
+		return lookup.Origin(sourceKey)
+	}
+	return optional.OfValue(Origin{
+		Source: source.Name(),
+		Key:    sourceKey})
+}
+
+func (this *Environment) resolveProperty(key string) any {
+	source, sourceKey := this.lookupPropertySource(key)
+	if source == nil {
+		list := this.lookupRawList(key)
+		if !list.Present() {
+			panic(NewPropertyNotFoundError(key))
+		}
+		return this.ResolveRequiredPlaceholders(list.Value())
+	}
+	defer err.Catch(func(e any) {
+		panic(withProperty(e, key, source.Name()))
+	})
+	if dotenv, ok := source.(*DotenvPropertySource); ok && dotenv.literal(sourceKey) {
+		return source.Property(sourceKey)
+	}
+	return this.ResolveRequiredPlaceholders(source.Property(sourceKey))
+}
+
+func (this *Environment) resolvePropertyAs(key string, t reflect.Type) any {
+	if t.Kind() == reflect.Map {
+		value := reflect.New(t).Elem()
+		if !bindMap(this, key, value) {
+			panic(NewPropertyNotFoundError(key))
+		}
+		return value.Interface()
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		// element by element, joining them with commas would split elements containing commas
+		if indexes := this.propertyIndexes(key); len(indexes) > 0 {
+			value := reflect.New(t).Elem()
+			bindList(this, key, value, indexes)
+			return value.Interface()
+		}
+	}
+	sourceName := ""
+	if source, _ := this.lookupPropertySource(key); source != nil {
+		sourceName = source.Name()
+	}
+	value := this.resolveProperty(key)
+	defer err.Catch(func(e any) {
+		panic(withProperty(e, key, sourceName))
+	})
+	return convertAsType(value, t)
+}
+
+// elements missing between indexes of a list, so my.servers[50000000] does not allocate a list that long
+const maxIndexGap = 1000
+
+// Indexes of list elements defined for the key, 0 and 1 for my.servers[0] and MY_SERVERS_1_ of environment variables or .env files,
+// merged across property sources.
+// Empty if the source of the highest precedence defining the key has it as a plain value, like my.servers=a,b.
+// Panics if more than maxIndexGap elements are missing in between
+func (this *Environment) propertyIndexes(key string) []int {
+	indexes := make(map[int]bool)
+	canonical := canonicalForm(key) + "["
+	sources := append([]PropertySource{this.paramsPropertySource, this.environPropertySource}, collections.ReverseSlice(this.sources())...)
+	for _, source := range sources {
+		if source.Properties() == nil {
+			// preprocessors answer for the sources they transform
+			continue
+		}
+		envPrefix, envKeys := this.envKeyPrefixOf(source)
+		envCanonical := envPrefix + envVarPrefixForm(key)
+		if len(indexes) == 0 && (source.HasProperty(key) || envKeys && (source.HasProperty(envCanonical) || source.HasProperty(envPrefix+envVarCanonicalForm(key)))) {
+			return nil
+		}
+		for k := range source.Properties() {
+			var rest, end string
+			var found bool
+			if source != PropertySource(this.environPropertySource) {
+				rest, found = strings.CutPrefix(canonicalForm(k), canonical)
+				end = "]"
+			}
+			if !found && envKeys {
+				rest, found = strings.CutPrefix(k, envCanonical+"_")
+				end = "_"
+			}
+			// MY_SERVERS_0 ends with the index
+			if digits, _, closed := strings.Cut(rest, end); found && (closed || end == "_") {
+				if i, e := strconv.Atoi(digits); e == nil && i >= 0 {
+					indexes[i] = true
+				}
+			}
+		}
+	}
+	result := slices.Sorted(maps.Keys(indexes))
+	previous := -1
+	for _, index := range result {
+		if gap := index - previous - 1; gap > maxIndexGap {
+			panic(err.NewIllegalArgumentException(fmt.Sprintf("Cannot bind %s[%d], %d elements before it are not defined, at most %d may be missing", key, index, gap, maxIndexGap)))
+		}
+		previous = index
+	}
+	return result
 }
 
 func (this *Environment) ResolveRequiredPlaceholders(expression string) any {
@@ -101,7 +329,7 @@ func (this *Environment) MatchesProfiles(profiles ...string) bool {
 		return true
 	}
 	activeProfiles := collections.SliceToSet(this.activeProfiles)
-	processor := regex.PatternProcessorOf(regex.NewPatternBuilder().Next("{word:\\w+}|{sign:\\W}").Build())
+	processor := regex.PatternProcessorOf(regex.NewPatternBuilder().Next("{word:[\\w.-]+}|{sign:[^\\w.-]}").Build())
 	processor.OverrideResolve(func(match *regex.Match,
 		super func(*regex.Match) any) any {
 		word := match.NamedGroup("word")
@@ -124,7 +352,7 @@ func (this *Environment) MatchesProfiles(profiles ...string) bool {
 		}
 	})
 	for _, profile := range profiles {
-		if Value[bool](fmt.Sprintf("#{%v}", processor.ProcessRecursive(profile, false))) {
+		if convertAs[bool](this.ResolveRequiredPlaceholders(fmt.Sprintf("#{%v}", processor.ProcessRecursive(profile, false)))) {
 			return true
 		}
 	}
@@ -136,16 +364,77 @@ func (this *Environment) ActiveProfiles() []string {
 	return this.activeProfiles
 }
 
+// Command line arguments that are not options, like file names
+func (this *Environment) PositionalArgs() []string {
+	return this.paramsPropertySource.PositionalArgs()
+}
+
+// Defines flag for every property loaded from files and command line, with the given prefixes if any,
+// so that flags.Parse accepts them and -help lists them along with their raw values and origins.
+// Values overridden by environment variables, transformed by preprocessors or loaded from config trees are not shown,
+// settings of the environment itself, config.* and profiles.*, are not flags as they take effect on build only.
+// Properties valued true or false are boolean flags. Flags set on parse override the properties the same way command line arguments do.
+//
+//	flags := flag.NewFlagSet("myapp", flag.ExitOnError)
+//	env.Instance().RegisterFlags(flags, "server", "db")
+//	flags.Parse(os.Args[1:])
+func (this *Environment) RegisterFlags(flags *flag.FlagSet, prefixes ...string) *flag.FlagSet {
+	// source of the highest precedence listing the key
+	listed := make(map[string]PropertySource)
+	for _, source := range append(slices.Clone(this.sources()), this.paramsPropertySource) {
+		for key := range source.Properties() {
+			listed[key] = source
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(listed)) {
+		if flags.Lookup(key) != nil || hasPrefix(key, "config") || hasPrefix(key, "profiles") || (len(prefixes) > 0 && !slices.ContainsFunc(prefixes, func(prefix string) bool {
+			return hasPrefix(key, prefix)
+		})) {
+			continue
+		}
+		// raw value of the listing source is the default, so help shows neither decoded secrets nor resolved cached: values.
+		// Left out if the value comes from elsewhere, like an environment variable, or a preprocessor, like base64: or RSA:, transforms it
+		value := ""
+		_, configTree := listed[key].(*ConfigTreePropertySource)
+		if source, sourceKey := this.lookupPropertySource(key); source == listed[key] && !configTree && !slices.ContainsFunc(this.sources(), func(preprocessor PropertySource) bool {
+			return preprocessor.Properties() == nil && preprocessor.HasProperty(sourceKey)
+		}) {
+			value = source.Property(sourceKey)
+		}
+		flags.Var(&propertyFlag{
+			environment: this,
+			key:         key,
+			value:       value,
+			boolFlag:    isBool(value)}, key, this.Origin(key).Value().String())
+	}
+	// options of flags the application defines itself may take values
+	this.flagSets = append(this.flagSets, flags)
+	this.paramsPropertySource.parse(this.optionTakesValue)
+	return flags
+}
+
+// Locations and imports marked optional: that did not exist when the environment was built
+func (this *Environment) SkippedLocations() []string {
+	return slices.Clone(this.skippedLocations)
+}
+
 // first wins
 func (this *Environment) PropertySources() []PropertySource {
-	return collections.ReverseSlice(this.propertySources)
+	return collections.ReverseSlice(this.sources())
+}
+
+func (this *Environment) sources() []PropertySource {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+	return this.propertySources
 }
 
 // PROFILES_ACTIVE=dev,hsqldb
-func (this *Environment) loadEnvironmentVariables() {
+func (this *Environment) loadEnvironmentVariables(variables []string) {
 	environ := MapPropertySourceOf("Environment variables")
+	environ.exactKeys = true
 	pattern := regexp.MustCompile(regex.NewPatternBuilder().Next(`{key:[^=\s]+}={value:.*}`).Build())
-	for _, keyValue := range os.Environ() {
+	for _, keyValue := range variables {
 		for _, m := range pattern.FindAllStringSubmatchIndex(keyValue, -1) {
 			match := regex.MatchOf(pattern, keyValue, m)
 			environ.SetProperty(match.NamedGroup("key").Value(), match.NamedGroup("value").Value())
@@ -155,42 +444,96 @@ func (this *Environment) loadEnvironmentVariables() {
 }
 
 // --profiles.active=dev,hsqldb
-func (this *Environment) loadApplicationParameters() {
-	params := MapPropertySourceOf("Application parameters")
-	pattern := regexp.MustCompile(regex.NewPatternBuilder().Next(`--?{key:[^=\s]+}\s*=?{value:.*}`).Build())
-	for _, keyValue := range os.Args[1:] {
-		for _, m := range pattern.FindAllStringSubmatchIndex(keyValue, -1) {
-			match := regex.MatchOf(pattern, keyValue, m)
-			params.SetProperty(match.NamedGroup("key").Value(), match.NamedGroup("value").Value())
+func (this *Environment) loadApplicationParameters(args []string) {
+	this.paramsPropertySource = NewCommandLinePropertySource(args)
+	this.paramsPropertySource.parse(this.optionTakesValue)
+}
+
+// --key value takes the value for settings of the environment, config.* and profiles.*, keys defined with a value other than true or false,
+// and registered flags that are not boolean, so --verbose input.txt leaves input.txt positional unless verbose is known to take a value
+func (this *Environment) optionTakesValue(key string) bool {
+	if hasPrefix(key, "config") || hasPrefix(key, "profiles") {
+		return true
+	}
+	for _, flags := range this.flagSets {
+		if defined := flags.Lookup(key); defined != nil {
+			boolFlag, ok := defined.Value.(interface{ IsBoolFlag() bool })
+			return !ok || !boolFlag.IsBoolFlag()
+		}
+	}
+	sources := this.sources()
+	for i := len(sources) - 1; i >= 0; i-- {
+		if sources[i].Properties() != nil && sources[i].HasProperty(key) {
+			return !isBool(sources[i].Property(key))
 		}
 	}
-	this.paramsPropertySource = params
+	if envKey, ok := this.envKey(key); ok {
+		return !isBool(this.environPropertySource.Property(envKey))
+	}
+	return false
 }
 
 // last wins
 // application.yaml
 // application-<profile>.yaml
-func (this *Environment) loadApplicationConfiguration(bootstrapProfiles string) {
-	activeProfiles := objects.FirstNonZero(bootstrapProfiles, this.paramsPropertySource.properties["profiles.active"], this.environPropertySource.properties["PROFILES_ACTIVE"])
-	this.activeProfiles = lang.If(len(activeProfiles) == 0, this.activeProfiles, append(this.activeProfiles, strings.Split(activeProfiles, ",")...))
-	configName := objects.FirstNonZero(this.paramsPropertySource.properties["config.name"], this.environPropertySource.properties["CONFIG_NAME"], "application")
-	defaultLocation := "./,./config/"
-	additionalLocation := objects.FirstNonZero(this.paramsPropertySource.properties["config.additional-location"], this.environPropertySource.properties["CONFIG_ADDITIONALLOCATION"])
+func (this *Environment) loadApplicationConfiguration(builder *Builder) {
+	envPrefix := objects.FirstNonZero(builder.envPrefix, this.paramsPropertySource.properties["config.env-prefix"], this.environPropertySource.properties["CONFIG_ENVPREFIX"])
+	this.envPrefix = lang.If(len(envPrefix) == 0, "", strings.ToUpper(strings.TrimSuffix(envPrefix, "_"))+"_")
+	this.keyTags = append([]string{ConfigTag}, splitList(objects.FirstNonZero(strings.Join(builder.keyTags, ","), this.setting("config.key-tags")))...)
+	this.defaultProfile = objects.FirstNonZero(builder.defaultProfile, this.setting("profiles.default"), "default")
+	validateProfiles([]string{this.defaultProfile}, "profiles.default")
+	this.activatedProfiles = splitList(objects.FirstNonZero(strings.Join(builder.profiles, ","), this.setting("profiles.active")))
+	validateProfiles(this.activatedProfiles, "profiles.active")
+	this.includedProfiles = splitList(this.environPropertySource.properties[this.envPrefix+"PROFILES_INCLUDE"])
+	validateProfiles(this.includedProfiles, "profiles.include")
+	this.profileGroups = make(map[string][]string)
+	this.matchedProfiles = make(map[string]bool)
+	this.applyProfiles(this.paramsPropertySource)
+	configName := objects.FirstNonZero(builder.configName, this.setting("config.name"), "application")
+	defaultLocation := "optional:./,optional:./config/"
+	additionalLocation := objects.FirstNonZero(strings.Join(builder.additionalLocations, ","), this.setting("config.additional-location"))
 	extendedDefaultLocation := lang.If(len(additionalLocation) == 0, defaultLocation, defaultLocation+","+additionalLocation)
-	configLocation := objects.FirstNonZero(this.paramsPropertySource.properties["config.location"], this.environPropertySource.properties["CONFIG_LOCATION"])
+	configLocation := objects.FirstNonZero(strings.Join(builder.locations, ","), this.setting("config.location"))
 	extendedConfigLocation := lang.If(len(additionalLocation) == 0, configLocation, additionalLocation+","+configLocation)
 	resolvedConfigLocation := lang.If(len(configLocation) == 0, extendedDefaultLocation, extendedConfigLocation)
 
 	for _, location := range strings.Split(resolvedConfigLocation, ",") {
+		declaredIn := lang.If(slices.Contains(strings.Split(additionalLocation, ","), location), "config.additional-location", "config.location")
 		for i := 0; i < len(this.activeProfiles); i++ {
+			this.loadedProfiles = i + 1
 			for _, locationGroup := range strings.Split(location, ";") {
-				this.loadConfiguration(locationGroup, configName, this.activeProfiles[i])
+				locationGroup, optional := strings.CutPrefix(locationGroup, optionalPrefix)
+				this.loadConfiguration(this.workingDirLocation(builder.workingDir, locationGroup), configName, this.activeProfiles[i], declaredIn, optional)
 			}
 		}
 	}
+	this.loadSkippedDocuments()
+	for _, profile := range this.activeProfiles[1:] {
+		if !this.matchedProfiles[profile] {
+			this.logger.Warn("no profile-specific file or document found for active profile", "profile", profile)
+		}
+	}
 }
 
-func (this *Environment) loadConfiguration(location, name, profile string) {
+// setting of the environment itself, like config.location argument or CONFIG_LOCATION environment variable
+func (this *Environment) setting(key string) string {
+	if this.paramsPropertySource.HasProperty(key) {
+		return this.paramsPropertySource.Property(key)
+	}
+	return this.environPropertySource.properties[this.envPrefix+envVarCanonicalForm(key)]
+}
+
+func (this *Environment) workingDirLocation(workingDir, location string) string {
+	if fsys, _ := this.fileSystemOf(location); fsys != nil {
+		return location
+	}
+	if len(workingDir) == 0 || filepath.IsAbs(location) || strings.HasPrefix(location, "/") || strings.HasPrefix(location, "~/") {
+		return location
+	}
+	return files.RelativePath(filepath.ToSlash(workingDir)+"/", location) + lang.If(strings.HasSuffix(location, "/"), "/", "")
+}
+
+func (this *Environment) loadConfiguration(location, name, profile, declaredIn string, optional bool) {
 	location = filepath.ToSlash(location)
 	var fantomExt string
 	for _, m := range locationPattern.FindAllStringSubmatchIndex(location, -1) {
@@ -198,46 +541,235 @@ func (this *Environment) loadConfiguration(location, name, profile string) {
 		location = match.NamedGroup("location").Value()
 		fantomExt = match.NamedGroup("fantomExt").Value()
 	}
+	base := profile == this.defaultProfile
+	// the location itself must exist, profile specific files within it or next to it may not
+	if base && !this.exists(location) {
+		this.locationNotFound(location, declaredIn, optional)
+	}
 
+	var paths []string
 	if strings.HasSuffix(location, "/") {
-		this.loadFile(files.RelativePath(location, lang.If(profile == "default", name+".yml", name+"-"+profile+".yml")), fantomExt)
-		this.loadFile(files.RelativePath(location, lang.If(profile == "default", name+".yaml", name+"-"+profile+".yaml")), fantomExt)
-		this.loadFile(files.RelativePath(location, lang.If(profile == "default", name+".properties", name+"-"+profile+".properties")), fantomExt)
+		for _, ext := range configFileExtensions {
+			paths = append(paths, files.RelativePath(location, lang.If(base, name+ext, name+"-"+profile+ext)))
+		}
 	} else if len(fantomExt) > 0 {
-		this.loadFile(lang.If(profile == "default", location, location+"-"+profile), fantomExt)
+		paths = append(paths, lang.If(base, location, location+"-"+profile))
 	} else {
 		ext := filepath.Ext(location)
-		this.loadFile(lang.If(profile == "default", location, location[:len(location)-len(ext)]+"-"+profile+ext), fantomExt)
+		paths = append(paths, lang.If(base, location, location[:len(location)-len(ext)]+"-"+profile+ext))
+	}
+	for _, path := range paths {
+		this.logger.Debug("probing config location", "path", path, "profile", profile)
+		if !base && this.exists(path) {
+			this.matchedProfiles[profile] = true
+		}
+		this.loadFile(path, fantomExt)
 	}
 }
 
 func (this *Environment) loadFile(path, fantomExt string) {
-	if !files.Exists(path) {
+	if !this.exists(path) {
 		return
 	}
-	var result PropertySource
-	fmt.Printf("loading properties from %s\n", path)
+	canonical := this.canonicalPath(path)
+	for i, loading := range this.loadingFiles {
+		if loading.canonical == canonical {
+			var chain []string
+			for _, file := range this.loadingFiles[i:] {
+				chain = append(chain, file.path)
+			}
+			panic(NewImportCycleError(append(chain, path)))
+		}
+	}
+	for _, loaded := range this.loadedFiles {
+		if loaded.canonical == canonical {
+			return
+		}
+	}
 	ext := objects.FirstNonZero(fantomExt, filepath.Ext(path))
 	lang.Assert(len(ext) != 0, "Cannot load from location %s. If location supposed to be a directory use '/' at the end. Otherwise provide extension hint in square brackets like [.properties] to derive property source type", path)
-	file := optional.OfCommaErr(os.Open(path)).OrElsePanic("Cannot open file %s", path)
-	defer file.Close()
-	content := string(optional.OfCommaErr(io.ReadAll(file)).OrElsePanic("Cannot read from %s", path))
+	this.logger.Info("loading properties", "path", path, "type", ext)
+	file := &loadedFile{
+		path:      path,
+		canonical: canonical,
+		ext:       ext,
+		stamp:     this.stat(path).OrElse(fileStamp{})}
+	this.loadedFiles = append(this.loadedFiles, file)
+	this.loadingFiles = append(this.loadingFiles, file)
+	defer func() {
+		this.loadingFiles = this.loadingFiles[:len(this.loadingFiles)-1]
+	}()
+	for _, result := range this.parseFile(path, ext) {
+		if !this.activeDocument(result) {
+			// profiles may still be activated by later documents and locations
+			var after PropertySource
+			if sources := this.sources(); len(sources) > 0 {
+				after = sources[len(sources)-1]
+			}
+			this.skippedDocuments = append(this.skippedDocuments, skippedDocument{
+				file:      file,
+				source:    result,
+				after:     after,
+				fileIndex: len(file.sources)})
+			continue
+		}
+		this.WithPropertySource(result)
+		file.sources = append(file.sources, result)
+		this.applyProfiles(result)
+		if result.HasProperty("config.import") {
+			for _, location := range strings.Split(result.Property("config.import"), ",") {
+				this.loadImport(path, location)
+			}
+		}
+	}
+}
+
+// Second pass once profiles are final, as documents skipped earlier may match profiles activated after them.
+// Documents are inserted where they would have been loaded, their profile properties are ignored as profiles are final
+func (this *Environment) loadSkippedDocuments() {
+	next := make(map[PropertySource]PropertySource)
+	inserted := make(map[*loadedFile]int)
+	for i := 0; i < len(this.skippedDocuments); i++ {
+		skipped := this.skippedDocuments[i]
+		if !this.activeDocument(skipped.source) {
+			continue
+		}
+		this.logger.Info("loading document activated by profiles", "path", skipped.file.path, "profiles", skipped.source.Property("config.activate.on-profile"))
+		// documents following the same property source keep their order
+		after := skipped.after
+		for chained, ok := next[after]; ok; chained, ok = next[after] {
+			after = chained
+		}
+		next[after] = skipped.source
+		this.insertPropertySource(after, skipped.source)
+		skipped.file.sources = slices.Insert(skipped.file.sources, skipped.fileIndex+inserted[skipped.file], skipped.source)
+		inserted[skipped.file]++
+		for _, key := range []string{"profiles.active", "profiles.include"} {
+			if len(listProperty(skipped.source, key)) > 0 {
+				this.logger.Warn("profiles are final, ignoring profiles of document activated by them", "path", skipped.file.path, "key", key)
+			}
+		}
+		if skipped.source.HasProperty("config.import") {
+			this.loadingFiles = append(this.loadingFiles, skipped.file)
+			for _, location := range strings.Split(skipped.source.Property("config.import"), ",") {
+				this.loadImport(skipped.file.path, location)
+			}
+			this.loadingFiles = this.loadingFiles[:len(this.loadingFiles)-1]
+		}
+	}
+	this.skippedDocuments = nil
+}
+
+// profiles.active unless set by builder, command line or environment, profiles.include and profiles.group.<name> add up
+func (this *Environment) applyProfiles(source PropertySource) {
+	if len(this.activatedProfiles) == 0 {
+		this.activatedProfiles = validateProfiles(listProperty(source, "profiles.active"), source.Name())
+	}
+	this.includedProfiles = append(this.includedProfiles, validateProfiles(listProperty(source, "profiles.include"), source.Name())...)
+	for key := range source.Properties() {
+		if group, ok := strings.CutPrefix(key, "profiles.group."); ok {
+			group, _, _ = strings.Cut(group, "[")
+			validateProfiles([]string{group}, source.Name())
+			this.profileGroups[group] = validateProfiles(listProperty(source, "profiles.group."+group), source.Name())
+		}
+	}
+
+	// default, included then activated profiles, each followed by members of its group
+	expanded := []string{this.defaultProfile}
+	var expand func(profile string)
+	expand = func(profile string) {
+		if slices.Contains(expanded, profile) {
+			return
+		}
+		expanded = append(expanded, profile)
+		for _, member := range this.profileGroups[profile] {
+			expand(member)
+		}
+	}
+	for _, profile := range append(slices.Clone(this.includedProfiles), this.activatedProfiles...) {
+		expand(profile)
+	}
+	for _, profile := range expanded {
+		if !slices.Contains(this.activeProfiles, profile) {
+			this.logger.Info("activating profile", "profile", profile)
+		}
+	}
+	// profiles the current location is already loaded for keep their position
+	loaded := slices.Clone(this.activeProfiles[:this.loadedProfiles])
+	this.activeProfiles = append(loaded, slices.DeleteFunc(expanded, func(profile string) bool {
+		return slices.Contains(loaded, profile)
+	})...)
+}
+
+var profileExpressionPattern = regexp.MustCompile(`[\w.-]+`)
+
+// letters, digits, '-', '_' or '.', so that profile expressions like prod-eu & !cloud stay unambiguous
+var profilePattern = regexp.MustCompile(`^[A-Za-z0-9]([\w.-]*[A-Za-z0-9])?$`)
+
+func validateProfiles(profiles []string, declaredIn string) []string {
+	for _, profile := range profiles {
+		lang.Assert(profilePattern.MatchString(profile), "Invalid profile '%s' declared in %s. Profile must contain only letters, digits, '-', '_' or '.' and start and end with a letter or digit", profile, declaredIn)
+	}
+	return profiles
+}
+
+// comma separated value or list, like profiles.include=a,b or profiles.include[0]=a
+func listProperty(source PropertySource, key string) []string {
+	if source.HasProperty(key) {
+		return splitList(source.Property(key))
+	}
+	var result []string
+	for i := 0; source.HasProperty(fmt.Sprintf("%s[%d]", key, i)); i++ {
+		result = append(result, splitList(source.Property(fmt.Sprintf("%s[%d]", key, i)))...)
+	}
+	return result
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// document applies unless its config.activate.on-profile expression, like prod & !cloud, does not match active profiles
+func (this *Environment) activeDocument(source PropertySource) bool {
+	if !source.HasProperty("config.activate.on-profile") {
+		return true
+	}
+	expression := source.Property("config.activate.on-profile")
+	for _, profile := range profileExpressionPattern.FindAllString(expression, -1) {
+		this.matchedProfiles[profile] = true
+	}
+	return this.MatchesProfiles(expression)
+}
+
+// property source per document of the file
+func (this *Environment) parseFile(path, ext string) []PropertySource {
+	if ext == configTreePrefix {
+		return []PropertySource{NewConfigTreePropertySource(path, this.dirFS(path))}
+	}
+	content := this.readFile(path)
 	switch ext {
 	case ".properties":
-		result = NewPropertiesPropertySource(path, content)
+		return []PropertySource{NewPropertiesPropertySource(path, content)}
+	case ".json":
+		return []PropertySource{NewJsonPropertySource(path, content)}
+	case ".toml":
+		return []PropertySource{NewTomlPropertySource(path, content)}
+	case ".env":
+		return []PropertySource{NewDotenvPropertySource(path, content, this.environPropertySource)}
 	case ".yaml", ".yml":
-		result = NewYamlPropertySource(path, content)
-	default:
-		panic(err.NewRuntimeException(fmt.Sprintf("Cannot load from %s as %s file type is not supported. Use extension hint in square brackets like .env[.properties] to derive property source type", path, ext)))
-	}
-	this.propertySources = append(this.propertySources, result)
-	if result.HasProperty("profiles.active") && len(this.activeProfiles) == 1 && this.activeProfiles[0] == "default" {
-		this.activeProfiles = append(this.activeProfiles, strings.Split(result.Property("profiles.active"), ",")...)
-	}
-	if result.HasProperty("config.import") {
-		for _, location := range strings.Split(result.Property("config.import"), ",") {
-			this.loadImport(path, location)
+		var result []PropertySource
+		for _, source := range NewYamlPropertySources(path, content) {
+			result = append(result, source)
 		}
+		return result
+	default:
+		panic(err.NewRuntimeException(fmt.Sprintf("Cannot load from %s as %s file type is not supported. Use extension hint in square brackets like myconfig[.properties] to derive property source type", path, ext)))
 	}
 }
 
@@ -249,12 +781,217 @@ func (this *Environment) loadImport(path, location string) {
 		fantomExt = match.NamedGroup("fantomExt").Value()
 	}
 	location = filepath.ToSlash(location)
-	lang.Assert(!strings.HasSuffix(location, "/"), "Cannot load from location %s defined in %s. Directory import is not supported", location, path)
-	this.loadFile(files.RelativePath(path, location), fantomExt)
+	location, optional := strings.CutPrefix(location, optionalPrefix)
+	location, configTree := strings.CutPrefix(location, configTreePrefix)
+	lang.Assert(configTree || !strings.HasSuffix(location, "/"), "Cannot load from location %s defined in %s. Directory import is supported for config trees only, like configtree:%s", location, path, location)
+	location = this.importPath(path, location)
+	this.logger.Debug("following import", "location", location, "source", path, "optional", optional)
+	if !this.exists(location) {
+		this.locationNotFound(location, path, optional)
+		return
+	}
+	this.loadFile(location, lang.If(configTree, configTreePrefix, fantomExt))
+}
+
+// location relative to the declaring file, within the same file system if the file belongs to one registered with Builder.FS
+func (this *Environment) importPath(path, location string) string {
+	if fsys, _ := this.fileSystemOf(location); fsys != nil {
+		return location
+	}
+	if fsys, name := this.fileSystemOf(path); fsys != nil && !strings.HasPrefix(location, "/") && !strings.HasPrefix(location, "~/") {
+		// filepath.Join would clean embed:config/../shared.properties to shared.properties on disk
+		return path[:strings.Index(path, ":")+1] + pathpkg.Join(pathpkg.Dir(name), location)
+	}
+	return files.RelativePath(path, location)
+}
+
+func (this *Environment) locationNotFound(location, declaredIn string, optional bool) {
+	if !optional {
+		panic(NewLocationNotFoundError(location, declaredIn))
+	}
+	this.logger.Debug("skipping optional location that does not exist", "location", location, "source", declaredIn)
+	this.skippedLocations = append(this.skippedLocations, location)
+}
+
+// "embed:config/application.yaml" is looked up as "config/application.yaml" in the file system registered as "embed" with Builder.FS
+func (this *Environment) fileSystemOf(path string) (fs.FS, string) {
+	if i := strings.Index(path, ":"); i > 1 {
+		if fsys, ok := this.fileSystems[path[:i]]; ok {
+			name := strings.TrimPrefix(pathpkg.Clean("/"+path[i+1:]), "/")
+			return fsys, lang.If(len(name) == 0, ".", name)
+		}
+	}
+	return nil, path
 }
 
-func (this *Environment) envVarCanonicalForm(key string) string {
-	return strings.ToUpper(str.ReplaceChars(key, envVarCanonicalFormTranslationRule))
+func (this *Environment) canonicalPath(path string) string {
+	if fsys, name := this.fileSystemOf(path); fsys != nil {
+		return path[:strings.Index(path, ":")+1] + name
+	}
+	path = optional.OfCommaErr(filepath.Abs(path)).OrElse(path)
+	return optional.OfCommaErr(filepath.EvalSymlinks(path)).OrElse(path)
+}
+
+func (this *Environment) exists(path string) bool {
+	if fsys, name := this.fileSystemOf(path); fsys != nil {
+		_, e := fs.Stat(fsys, name)
+		return e == nil
+	}
+	return files.Exists(path)
+}
+
+func (this *Environment) stat(path string) *optional.Optional[fileStamp] {
+	var info fs.FileInfo
+	var e error
+	if fsys, name := this.fileSystemOf(path); fsys != nil {
+		info, e = fs.Stat(fsys, name)
+	} else {
+		info, e = os.Stat(path)
+	}
+	if e != nil {
+		return optional.OfEmpty[fileStamp]()
+	}
+	if info.IsDir() {
+		return this.treeStamp(path)
+	}
+	return optional.OfValue(fileStamp{
+		modTime: info.ModTime(),
+		size:    info.Size()})
+}
+
+// config tree changes with any of its files, Kubernetes swaps all of them at once by relinking ..data
+func (this *Environment) treeStamp(path string) (result *optional.Optional[fileStamp]) {
+	defer err.Catch(func(any) {
+		result = optional.OfEmpty[fileStamp]()
+	})
+	var stamp fileStamp
+	walkConfigTree(path, this.dirFS(path), ".", func(_ string, info fs.FileInfo) {
+		stamp.modTime = lang.If(info.ModTime().After(stamp.modTime), info.ModTime(), stamp.modTime)
+		stamp.size += info.Size()
+	})
+	return optional.OfValue(stamp)
+}
+
+func (this *Environment) dirFS(path string) fs.FS {
+	if fsys, name := this.fileSystemOf(path); fsys != nil {
+		return optional.OfCommaErr(fs.Sub(fsys, name)).OrElsePanic("Cannot read config tree %s", path)
+	}
+	return os.DirFS(path)
+}
+
+func (this *Environment) readFile(path string) string {
+	if fsys, name := this.fileSystemOf(path); fsys != nil {
+		return string(optional.OfCommaErr(fs.ReadFile(fsys, name)).OrElsePanic("Cannot read from %s", path))
+	}
+	file := optional.OfCommaErr(os.Open(path)).OrElsePanic("Cannot open file %s", path)
+	defer file.Close()
+	return string(optional.OfCommaErr(io.ReadAll(file)).OrElsePanic("Cannot read from %s", path))
+}
+
+// any property is defined with the key or nested under it, like db.pool.size or DB_POOL_SIZE for db or db.pool
+func (this *Environment) hasProperties(prefix string) bool {
+	canonical := canonicalForm(prefix)
+	for _, source := range append(slices.Clone(this.sources()), this.paramsPropertySource, this.environPropertySource) {
+		envPrefix, envKeys := this.envKeyPrefixOf(source)
+		envCanonical := envPrefix + envVarPrefixForm(prefix)
+		for key := range source.Properties() {
+			if source != PropertySource(this.environPropertySource) && hasPrefix(canonicalForm(key), canonical) {
+				return true
+			}
+			if envKeys && (key == envCanonical || strings.HasPrefix(key, envCanonical+"_")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Immediate children of the prefix across property sources, primary and [X-Request-Id] for
+// datasources.primary.url and headers[X-Request-Id], keyed by canonical form. Spelling of the source of the highest precedence wins,
+// environment variables and .env files contribute lower case names, DATASOURCES_REPLICA_URL adds replica
+func (this *Environment) propertyChildren(prefix string) map[string]string {
+	children := make(map[string]string)
+	prefixSegments := keySegments(canonicalForm(prefix))
+	sources := append([]PropertySource{this.paramsPropertySource}, collections.ReverseSlice(this.sources())...)
+	for _, source := range append(sources, this.environPropertySource) {
+		envPrefix, envKeys := this.envKeyPrefixOf(source)
+		for key := range source.Properties() {
+			if rest, found := strings.CutPrefix(key, envPrefix+envVarPrefixForm(prefix)+"_"); envKeys && found {
+				child, _, _ := strings.Cut(rest, "_")
+				if _, found := children[canonicalForm(child)]; !found && len(child) > 0 {
+					children[canonicalForm(child)] = strings.ToLower(child)
+				}
+				continue
+			}
+			if source == PropertySource(this.environPropertySource) {
+				continue
+			}
+			segments := keySegments(key)
+			if len(segments) <= len(prefixSegments) {
+				continue
+			}
+			matches := true
+			for i, segment := range prefixSegments {
+				matches = matches && canonicalForm(segments[i]) == segment
+			}
+			if child := segments[len(prefixSegments)]; matches && len(child) > 0 {
+				if _, found := children[canonicalForm(child)]; !found {
+					children[canonicalForm(child)] = child
+				}
+			}
+		}
+	}
+	return children
+}
+
+// environment variables, with the prefix of the environment, and .env files key properties like DB_HOSTS_0_
+func (this *Environment) envKeyPrefixOf(source PropertySource) (string, bool) {
+	if source == PropertySource(this.environPropertySource) {
+		return this.envPrefix, true
+	}
+	_, dotenv := source.(*DotenvPropertySource)
+	return "", dotenv
+}
+
+// path segments of the key, a.b[c.d][0] is a, b, [c.d] and [0]
+func keySegments(key string) []string {
+	var segments []string
+	for len(key) > 0 {
+		end := strings.IndexAny(key, ".[")
+		if key[0] == '[' {
+			end = strings.IndexByte(key, ']') + 1
+		}
+		if end <= 0 {
+			return append(segments, key)
+		}
+		segments = append(segments, key[:end])
+		key = strings.TrimPrefix(key[end:], ".")
+	}
+	return segments
+}
+
+// key is the prefix itself or nested under it, like db.url or db[0] for db
+func hasPrefix(key, prefix string) bool {
+	return len(prefix) == 0 || key == prefix || strings.HasPrefix(key, prefix+".") || strings.HasPrefix(key, prefix+"[")
+}
+
+var canonicalFormReplacer = strings.NewReplacer("-", "", "_", "")
+
+// lower case without dashes and underscores, so max-idle-conns, maxIdleConns and max_idle_conns are the same key
+func canonicalForm(key string) string {
+	return strings.ToLower(canonicalFormReplacer.Replace(key))
+}
+
+var envVarIndexReplacer = strings.NewReplacer("].", "]", "][", "[")
+
+// MY_SERVICE_0_OTHER for my.service[0].other, MY_SERVERS_0_ for my.servers[0], MAIN_LOGSTARTUPINFO for main.log-startup-info
+func envVarCanonicalForm(key string) string {
+	return strings.ToUpper(str.ReplaceChars(envVarIndexReplacer.Replace(key), envVarCanonicalFormTranslationRule))
+}
+
+// envVarCanonicalForm without trailing underscore of an index, MY_SERVERS_0 for my.servers[0], nested properties continue with _
+func envVarPrefixForm(key string) string {
+	return strings.TrimSuffix(envVarCanonicalForm(key), "_")
 }
 
 // Add custom property source to implement additional logic for properties processing, like property=base64:dGVzdAo=.
@@ -262,10 +999,177 @@ func (this *Environment) envVarCanonicalForm(key string) string {
 //
 //	var _ = env.Instance().WithPropertySource(env.NewRsaPropertySource())
 func (this *Environment) WithPropertySource(source PropertySource) *Environment {
+	if aware, ok := source.(EnvironmentAware); ok {
+		aware.SetEnvironment(this)
+	}
+	this.logger.Debug("registering property source", "name", source.Name())
+	this.mu.Lock()
 	this.propertySources = append(this.propertySources, source)
+	this.mu.Unlock()
+	// --key value takes the value if the source defines key
+	this.paramsPropertySource.parse(this.optionTakesValue)
 	return this
 }
 
+// right after the given source, first if nil
+func (this *Environment) insertPropertySource(after, source PropertySource) {
+	if aware, ok := source.(EnvironmentAware); ok {
+		aware.SetEnvironment(this)
+	}
+	this.mu.Lock()
+	position := 0
+	if after != nil {
+		position = slices.Index(this.propertySources, after) + 1
+	}
+	this.propertySources = slices.Insert(slices.Clone(this.propertySources), position, source)
+	this.mu.Unlock()
+	this.paramsPropertySource.parse(this.optionTakesValue)
+}
+
+// Poll configuration files loaded at startup, including imports, for changes every interval.
+// Changed files are parsed again and their property sources replaced, then OnChange listeners are notified.
+// Files that were not present at startup, or had no document active, are not picked up. Returns function to stop watching.
+//
+//	stop := env.Instance().Watch(10 * time.Second)
+//	defer stop()
+func (this *Environment) Watch(interval time.Duration) func() {
+	timer := util.NewTimer()
+	timer.ScheduleWithDelayPeriod(util.NewTimerTask(this.reload), interval, interval)
+	return timer.Cancel
+}
+
+// Subscribe to changes of properties with the given prefix detected by Watch, empty prefix for all properties.
+//
+//	env.Instance().OnChange("db", func(event env.ChangeEvent) {
+//		for _, change := range event.Changes {
+//			slog.Info("db property changed", "key", change.Key, "value", change.NewValue.OrElse(""))
+//		}
+//	})
+func (this *Environment) OnChange(prefix string, listener func(ChangeEvent)) *Environment {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.changeListeners = append(this.changeListeners, &changeListener{
+		prefix:   prefix,
+		listener: listener})
+	return this
+}
+
+func (this *Environment) reload() {
+	concurrent.Synchronized(&this.reloadMu, func() {
+		for _, file := range this.loadedFiles {
+			stamp := this.stat(file.path)
+			if !stamp.Present() || stamp.Value() == file.stamp {
+				continue
+			}
+			file.stamp = stamp.Value()
+			this.reloadFile(file)
+		}
+	})
+}
+
+func (this *Environment) reloadFile(file *loadedFile) {
+	// keep previous properties if the file cannot be parsed, like when it is being written
+	defer err.Catch(func(e any) {
+		this.logger.Warn("cannot reload properties, keeping previous ones", "path", file.path, "error", e)
+	})
+	this.logger.Info("reloading properties", "path", file.path)
+	var sources []PropertySource
+	for _, source := range this.parseFile(file.path, file.ext) {
+		if this.activeDocument(source) {
+			sources = append(sources, source)
+		}
+	}
+	keys := make(map[string]any)
+	for _, source := range append(slices.Clone(file.sources), sources...) {
+		for key := range source.Properties() {
+			keys[key] = nil
+		}
+	}
+	before := this.rawProperties(keys)
+	this.replacePropertySources(file.sources, sources)
+	file.sources = sources
+	after := this.rawProperties(keys)
+
+	var changes []PropertyChange
+	for key := range keys {
+		if before[key].Present() != after[key].Present() || before[key].OrElse("") != after[key].OrElse("") {
+			changes = append(changes, PropertyChange{
+				Key:      key,
+				OldValue: before[key],
+				NewValue: after[key]})
+		}
+	}
+	slices.SortFunc(changes, func(a, b PropertyChange) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	this.notifyChangeListeners(file.path, changes)
+}
+
+func (this *Environment) rawProperties(keys map[string]any) map[string]*optional.Optional[string] {
+	result := make(map[string]*optional.Optional[string], len(keys))
+	for key := range keys {
+		result[key] = this.lookupRawProperty(key)
+	}
+	return result
+}
+
+// documents are replaced in order, documents added to the file are inserted after the last previous one
+func (this *Environment) replacePropertySources(previous, sources []PropertySource) {
+	for _, source := range sources {
+		if aware, ok := source.(EnvironmentAware); ok {
+			aware.SetEnvironment(this)
+		}
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	var positions []int
+	for i, source := range this.propertySources {
+		if slices.Contains(previous, source) {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) == 0 {
+		return
+	}
+	propertySources := slices.Clone(this.propertySources)
+	for i := len(positions) - 1; i >= 0; i-- {
+		if i < len(sources) {
+			propertySources[positions[i]] = sources[i]
+		} else {
+			propertySources = slices.Delete(propertySources, positions[i], positions[i]+1)
+		}
+	}
+	if len(sources) > len(positions) {
+		last := positions[len(positions)-1]
+		propertySources = slices.Insert(propertySources, last+1, sources[len(positions):]...)
+	}
+	this.propertySources = propertySources
+}
+
+func (this *Environment) notifyChangeListeners(source string, changes []PropertyChange) {
+	this.mu.RLock()
+	listeners := slices.Clone(this.changeListeners)
+	this.mu.RUnlock()
+	for _, listener := range listeners {
+		var matched []PropertyChange
+		for _, change := range changes {
+			if hasPrefix(change.Key, listener.prefix) {
+				matched = append(matched, change)
+			}
+		}
+		if len(matched) > 0 {
+			this.notifyChangeListener(listener, ChangeEvent{
+				Source:  source,
+				Changes: matched})
+		}
+	}
+}
+
+func (this *Environment) notifyChangeListener(listener *changeListener, event ChangeEvent) {
+	defer err.Recover()
+	listener.listener(event)
+}
+
 // Add custom context variables to be evaluated.
 // See env.ExprProcessor for expressions and variables available by default.
 //
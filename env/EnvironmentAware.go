@@ -0,0 +1,7 @@
+package env
+
+// Implemented by property sources that need to look up other properties, like Base64PropertySource.
+// Environment.WithPropertySource hands itself over, so the source reads from the environment it belongs to.
+type EnvironmentAware interface {
+	SetEnvironment(environment *Environment)
+}
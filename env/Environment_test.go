@@ -0,0 +1,267 @@
+package env_test
+
+import (
+	"bytes"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-errr/go/err"
+	"github.com/go-external-config/go/env"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Environment_Profiles(t *testing.T) {
+	t.Run("should expand profile groups in declaration order", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.yaml"), []byte(`
+profiles:
+  group:
+    prod: [proddb, prodmq]
+    prodmq: [metrics]
+  include: common
+`), 0644))
+		for _, profile := range []string{"common", "prod", "proddb", "prodmq", "metrics"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "application-"+profile+".properties"), []byte("last="+profile+"\n"+profile+"=true\n"), 0644))
+		}
+
+		environment := env.NewBuilder().Args().Environ().Locations(dir + "/").Profiles("prod").Build()
+
+		require.Equal(t, []string{"default", "common", "prod", "proddb", "prodmq", "metrics"}, environment.ActiveProfiles())
+		require.Equal(t, "metrics", environment.Property("last"))
+		require.Equal(t, "true", environment.Property("proddb"))
+		require.Equal(t, "true", environment.Property("common"))
+		require.True(t, environment.MatchesProfiles("prodmq & metrics"))
+	})
+
+	t.Run("should include profiles along with profiles.active from file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.properties"), []byte("profiles.active=dev\nprofiles.include=local, debug\nprofiles.group.dev=h2\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application-h2.properties"), []byte("db.url=jdbc:h2:mem\n"), 0644))
+
+		environment := env.NewBuilder().Args("--profiles.include=cli").Environ().Locations(dir + "/").Build()
+
+		require.Equal(t, []string{"default", "cli", "local", "debug", "dev", "h2"}, environment.ActiveProfiles())
+		require.Equal(t, "jdbc:h2:mem", environment.Property("db.url"))
+	})
+
+	t.Run("should load files without profile suffix for the configured default profile", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.properties"), []byte("name=base\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application-prod-eu.properties"), []byte("region=eu\n"), 0644))
+
+		environment := env.NewBuilder().Args().Environ("PROFILES_DEFAULT=local").Locations(dir + "/").Profiles("prod-eu").Build()
+
+		require.Equal(t, []string{"local", "prod-eu"}, environment.ActiveProfiles())
+		require.Equal(t, "base", environment.Property("name"))
+		require.Equal(t, "eu", environment.Property("region"))
+		require.True(t, environment.MatchesProfiles("local & prod-eu"))
+		require.False(t, environment.MatchesProfiles("default"))
+	})
+
+	t.Run("should reject malformed profile names", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.properties"), []byte("profiles.include=dev&test\n"), 0644))
+
+		require.PanicsWithError(t, "Invalid profile 'prod eu' declared in profiles.active. Profile must contain only letters, digits, '-', '_' or '.' and start and end with a letter or digit", func() {
+			env.NewBuilder().Args().Environ().Locations(dir + "/").Profiles("prod eu").Build()
+		})
+		require.Panics(t, func() {
+			env.NewBuilder().Args().Environ().Locations(dir + "/").Build()
+		})
+	})
+
+	t.Run("should warn about active profiles without profile-specific configuration", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.yaml"), []byte("name: base\n---\nconfig.activate.on-profile: cloud\nname: cloud\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application-prod.yaml"), []byte("name: prod\n"), 0644))
+
+		var output bytes.Buffer
+		logger := slog.New(slog.NewTextHandler(&output, nil))
+
+		env.NewBuilder().Args().Environ().Locations(dir + "/").Profiles("prod,cloud,prdo").Logger(logger).Build()
+
+		require.Contains(t, output.String(), "level=INFO msg=\"loading properties\" path="+filepath.ToSlash(dir)+"/application-prod.yaml type=.yaml")
+		require.Contains(t, output.String(), "level=INFO msg=\"activating profile\" profile=cloud")
+		require.Contains(t, output.String(), "level=WARN msg=\"no profile-specific file or document found for active profile\" profile=prdo")
+		require.NotContains(t, output.String(), "level=WARN msg=\"no profile-specific file or document found for active profile\" profile=prod\n")
+		require.NotContains(t, output.String(), "level=WARN msg=\"no profile-specific file or document found for active profile\" profile=cloud")
+	})
+
+	t.Run("should load documents of profiles activated later", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "override"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.yaml"), []byte(`
+a: base
+b: base
+c: base
+---
+config.activate.on-profile: dev
+a: dev-doc
+b: dev-doc
+c: dev-doc
+---
+profiles.active: dev
+b: last
+`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "override", "application.yaml"), []byte(`
+c: override
+---
+config.activate.on-profile: prod
+d: prod-doc
+`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "override", "application.properties"), []byte("profiles.include=prod\n"), 0644))
+
+		environment := env.NewBuilder().Args().Environ().Locations(dir+"/", dir+"/override/").Build()
+
+		require.Equal(t, []string{"default", "prod", "dev"}, environment.ActiveProfiles())
+		require.Equal(t, "dev-doc", environment.Property("a"))
+		require.Equal(t, "last", environment.Property("b"))
+		require.Equal(t, "override", environment.Property("c"))
+		require.Equal(t, "prod-doc", environment.Property("d"))
+	})
+}
+
+func Test_Environment_Watch(t *testing.T) {
+	t.Run("should reload changed file and notify listeners", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "application.properties")
+		require.NoError(t, os.WriteFile(path, []byte("db.url=url1\ndb.user=user\nname=app\n"), 0644))
+		environment := env.NewBuilder().
+			Args("--db.user=cli").
+			Environ().
+			Locations(dir + "/").
+			Build()
+		events := make(chan env.ChangeEvent, 10)
+		environment.OnChange("db", func(event env.ChangeEvent) {
+			events <- event
+		})
+		stop := environment.Watch(10 * time.Millisecond)
+		defer stop()
+
+		require.NoError(t, os.WriteFile(path, []byte("db.url=url2\ndb.user=user2\ndb.pool=5\nname=app2\n"), 0644))
+
+		select {
+		case event := <-events:
+			require.Equal(t, filepath.ToSlash(path), event.Source)
+			require.Len(t, event.Changes, 2)
+			require.Equal(t, "db.pool", event.Changes[0].Key)
+			require.False(t, event.Changes[0].OldValue.Present())
+			require.Equal(t, "5", event.Changes[0].NewValue.Value())
+			require.Equal(t, "db.url", event.Changes[1].Key)
+			require.Equal(t, "url1", event.Changes[1].OldValue.Value())
+			require.Equal(t, "url2", event.Changes[1].NewValue.Value())
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "change event expected")
+		}
+		require.Equal(t, "url2", environment.Property("db.url"))
+		require.Equal(t, "cli", environment.Property("db.user"))
+		require.Equal(t, "app2", environment.Property("name"))
+	})
+}
+
+func Test_Environment_Import(t *testing.T) {
+	t.Run("should import file once however it is referred to", func(t *testing.T) {
+		dir := filepath.ToSlash(t.TempDir())
+		require.NoError(t, os.Mkdir(dir+"/shared", 0755))
+		require.NoError(t, os.Symlink(dir+"/shared", dir+"/link"))
+		require.NoError(t, os.WriteFile(dir+"/application.properties", []byte("config.import=a.properties,b.properties\n"), 0644))
+		require.NoError(t, os.WriteFile(dir+"/a.properties", []byte("config.import=shared/common.properties\nname=a\n"), 0644))
+		require.NoError(t, os.WriteFile(dir+"/b.properties", []byte("config.import=./link/common.properties\nname=b\n"), 0644))
+		require.NoError(t, os.WriteFile(dir+"/shared/common.properties", []byte("name=common\ncommon=true\n"), 0644))
+
+		environment := env.NewBuilder().Args().Environ().Locations(dir + "/").Build()
+
+		require.Equal(t, "b", environment.Property("name"))
+		require.Equal(t, "true", environment.Property("common"))
+		var common int
+		for _, source := range environment.PropertySources() {
+			if source.Properties() != nil && source.HasProperty("common") {
+				common++
+			}
+		}
+		require.Equal(t, 1, common)
+	})
+}
+
+func Test_Environment_RegisterFlags(t *testing.T) {
+	t.Run("should define flags for properties", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.properties"), []byte("server.port=8080\nserver.debug=false\ndb.url=jdbc\n"), 0644))
+		environment := env.NewBuilder().Args("--server.host=localhost").Environ().Locations(dir + "/").Build()
+		flags := flag.NewFlagSet("myapp", flag.ContinueOnError)
+		var output bytes.Buffer
+		flags.SetOutput(&output)
+
+		environment.RegisterFlags(flags, "server")
+		flags.PrintDefaults()
+
+		require.Nil(t, flags.Lookup("db.url"))
+		require.Contains(t, output.String(), "-server.port value\n    \t"+filepath.ToSlash(dir)+"/application.properties:1:13 (default 8080)")
+		require.Contains(t, output.String(), "-server.host value\n    \tApplication parameters [server.host] (default localhost)")
+
+		require.NoError(t, flags.Parse([]string{"-server.port=9000", "-server.debug", "rest"}))
+		require.Equal(t, "9000", environment.Property("server.port"))
+		require.Equal(t, "true", environment.Property("server.debug"))
+		require.Equal(t, []string{"rest"}, flags.Args())
+	})
+
+	t.Run("should take value for numeric properties and hide secrets", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args("--retries=1", "--port=0").
+			Environ().
+			Build().
+			WithPropertySource(env.MapPropertySourceOfMap("application.properties", map[string]string{
+				"secret":   "base64:c2VjcmV0",
+				"greeting": "hello ${name:world}"}))
+		flags := flag.NewFlagSet("myapp", flag.ContinueOnError)
+		var output bytes.Buffer
+		flags.SetOutput(&output)
+
+		environment.RegisterFlags(flags)
+		flags.PrintDefaults()
+
+		require.NotContains(t, output.String(), "(default secret)")
+		require.Contains(t, output.String(), "-secret value\n    \tapplication.properties [secret]\n")
+		require.Contains(t, output.String(), "(default hello ${name:world})")
+
+		require.NoError(t, flags.Parse([]string{"-retries", "3", "-port", "9", "rest"}))
+		require.Equal(t, "3", environment.Property("retries"))
+		require.Equal(t, "9", environment.Property("port"))
+		require.Equal(t, []string{"rest"}, flags.Args())
+	})
+
+	t.Run("should hide values of config trees and environment variables", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "secrets", "db"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "secrets", "db", "password"), []byte("hunter2\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.properties"), []byte("config.import=configtree:secrets/\napi.token=placeholder\napi.url=http://api\n"), 0644))
+		environment := env.NewBuilder().Args().Environ("API_TOKEN=realtoken").Locations(dir + "/").Build()
+		flags := flag.NewFlagSet("myapp", flag.ContinueOnError)
+		var output bytes.Buffer
+		flags.SetOutput(&output)
+
+		environment.RegisterFlags(flags)
+		flags.PrintDefaults()
+
+		require.Equal(t, "hunter2", environment.Property("db.password"))
+		require.NotContains(t, output.String(), "hunter2")
+		require.NotContains(t, output.String(), "realtoken")
+		require.NotContains(t, output.String(), "placeholder")
+		require.Contains(t, output.String(), "-api.token value\n    \tEnvironment variables [API_TOKEN]\n")
+		require.Contains(t, output.String(), "(default http://api)")
+		require.Nil(t, flags.Lookup("config.import"))
+	})
+}
+
+// error the function panics with, nil if it returns normally
+func catch(fn func()) (e error) {
+	defer err.Catch(func(cause any) {
+		e = cause.(error)
+	})
+	fn()
+	return nil
+}
@@ -18,8 +18,9 @@ import (
 // See expr-lang: https://expr-lang.org/docs/language-definition
 type ExprProcessor struct {
 	regex.PatternProcessor
-	context map[string]any
-	strict  bool
+	environment *Environment
+	context     map[string]any
+	strict      bool
 }
 
 func ExprProcessorOf(strict bool) *ExprProcessor {
@@ -61,23 +62,39 @@ func (this *ExprProcessor) Resolve(match *regex.Match,
 	}
 	prop := match.NamedGroup("prop")
 	if prop.Present() {
-		resolvedValue := Instance().lookupRawProperty(prop.Value())
+		resolvedValue := this.Environment().lookupRawProperty(prop.Value())
 		defaultValue := match.NamedGroup("defaultValue")
 		if resolvedValue.Present() {
 			resolved = fmt.Sprint(resolvedValue.Value())
 		} else if defaultValue.Present() {
 			resolved = defaultValue.Value()
 		} else {
-			panic(err.NewRuntimeException(fmt.Sprintf("Cannot resolve property %s", match.Expr())))
+			panic(NewPropertyNotFoundError(prop.Value()))
 		}
 	} else {
 		expression := objects.FirstNonZero(match.NamedGroup("expr").OrElse(""), match.NamedGroup("complex").OrElse(""))
-		resolved = optional.OfNilable(this.eval(expression, this.context)).OrElsePanic("Cannot evaluate expression %s", match.Expr())
+		result := optional.OfNilable(this.eval(expression, this.context))
+		if !result.Present() {
+			panic(NewExpressionError(expression, nil))
+		}
+		resolved = result.Value()
 	}
 	// fmt.Printf("ExprProcessor: %s -> %s\n", match.Expr(), resolved)
 	return resolved
 }
 
+// Environment properties are resolved against, env.Instance() unless set explicitly
+func (this *ExprProcessor) Environment() *Environment {
+	if this.environment == nil {
+		return Instance()
+	}
+	return this.environment
+}
+
+func (this *ExprProcessor) SetEnvironment(environment *Environment) {
+	this.environment = environment
+}
+
 func (this *ExprProcessor) Define(key string, value any) {
 	this.context[key] = value
 }
@@ -87,6 +104,9 @@ func (this *ExprProcessor) Reset() {
 }
 
 func (this *ExprProcessor) eval(input string, env any) any {
+	defer err.Catch(func(e any) {
+		panic(NewExpressionError(input, e))
+	})
 	config := conf.CreateNew()
 	config.Strict = true
 	tree := optional.OfCommaErr(parser.Parse(input)).OrElsePanic("Cannot parse expression")
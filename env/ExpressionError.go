@@ -0,0 +1,39 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-errr/go/err"
+)
+
+// ExpressionError reports a #{...} expression that cannot be parsed, compiled or evaluated.
+// Key and Source name the property whose value contains the expression, if known.
+type ExpressionError struct {
+	err.RuntimeException
+	Key        string
+	Source     string
+	Expression string
+}
+
+func NewExpressionError(expression string, cause any) *ExpressionError {
+	return &ExpressionError{
+		RuntimeException: *err.NewRuntimeExceptionWith("", cause, err.StackTrace(1)),
+		Expression:       expression}
+}
+
+func (this *ExpressionError) Error() string {
+	var message strings.Builder
+	fmt.Fprintf(&message, "Cannot evaluate expression %s", this.Expression)
+	if len(this.Key) > 0 {
+		fmt.Fprintf(&message, " for %s", this.Key)
+	}
+	if len(this.Source) > 0 {
+		fmt.Fprintf(&message, " from %s", this.Source)
+	}
+	return message.String()
+}
+
+func (this *ExpressionError) Format(s fmt.State, verb rune) {
+	this.DefaultFormat(s, verb, this)
+}
@@ -0,0 +1,29 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-errr/go/err"
+)
+
+// ImportCycleError reports config.import chain leading back to a file that is still being loaded,
+// like a.yaml -> b.yaml -> a.yaml.
+type ImportCycleError struct {
+	err.RuntimeException
+	Chain []string
+}
+
+func NewImportCycleError(chain []string) *ImportCycleError {
+	return &ImportCycleError{
+		RuntimeException: *err.NewRuntimeExceptionWith("", nil, err.StackTrace(1)),
+		Chain:            chain}
+}
+
+func (this *ImportCycleError) Error() string {
+	return fmt.Sprintf("Import cycle detected: %s", strings.Join(this.Chain, " -> "))
+}
+
+func (this *ImportCycleError) Format(s fmt.State, verb rune) {
+	this.DefaultFormat(s, verb, this)
+}
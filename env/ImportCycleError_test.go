@@ -0,0 +1,29 @@
+package env_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-external-config/go/env"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ImportCycleError(t *testing.T) {
+	t.Run("should report import cycle with the full chain", func(t *testing.T) {
+		dir := filepath.ToSlash(t.TempDir())
+		require.NoError(t, os.WriteFile(dir+"/application.yaml", []byte("config.import: a.yaml\n"), 0644))
+		require.NoError(t, os.WriteFile(dir+"/a.yaml", []byte("config.import: b.yaml\n"), 0644))
+		require.NoError(t, os.WriteFile(dir+"/b.yaml", []byte("config.import: ./a.yaml\n"), 0644))
+
+		e := catch(func() {
+			env.NewBuilder().Args().Environ().Locations(dir + "/").Build()
+		})
+
+		var cycle *env.ImportCycleError
+		require.True(t, errors.As(e, &cycle))
+		require.Equal(t, []string{dir + "/a.yaml", dir + "/b.yaml", dir + "/a.yaml"}, cycle.Chain)
+		require.Equal(t, "Import cycle detected: "+dir+"/a.yaml -> "+dir+"/b.yaml -> "+dir+"/a.yaml", e.Error())
+	})
+}
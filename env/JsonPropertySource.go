@@ -0,0 +1,45 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-errr/go/err"
+)
+
+// Property source of JSON document, flattened the same way as YAML:
+//
+//	{"my": {"servers": ["dev.example.com", "another.example.com"]}}
+//
+// becomes
+//
+//	my.servers[0]=dev.example.com
+//	my.servers[1]=another.example.com
+type JsonPropertySource struct {
+	MapPropertySource
+}
+
+func NewJsonPropertySource(name, json string) *JsonPropertySource {
+	jsonPropertySource := JsonPropertySource{
+		MapPropertySource: *MapPropertySourceOf(name)}
+	jsonPropertySource.SetProperties(jsonPropertySource.propertiesFromJson(json))
+	return &jsonPropertySource
+}
+
+func (this *JsonPropertySource) propertiesFromJson(jsonStr string) map[string]string {
+	properties := make(map[string]string)
+	if len(strings.TrimSpace(jsonStr)) == 0 {
+		return properties
+	}
+	var parsedJson any
+	decoder := json.NewDecoder(strings.NewReader(jsonStr))
+	// keep numbers as written, 1000000 rather than 1e+06
+	decoder.UseNumber()
+	e := decoder.Decode(&parsedJson)
+	if e != nil {
+		panic(err.NewRuntimeException(fmt.Sprintf("Unmarshalling failed: %v", e)))
+	}
+	flatten(parsedJson, "", properties)
+	return properties
+}
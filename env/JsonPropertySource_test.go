@@ -0,0 +1,47 @@
+package env_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-external-config/go/env"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JsonPropertySource_Resolve(t *testing.T) {
+	t.Run("should flatten objects and arrays", func(t *testing.T) {
+		source := env.NewJsonPropertySource("jsonPropertySource", `{
+	"a": {"key1": "value1", "key2": 2.5, "big": 1000000},
+	"enabled": true,
+	"servers": ["host1", "host2"],
+	"c": {"array": [{"name": "element1", "value": "#{3+2}"}, {"sub-array": [{"sub1": "#{${a.key2} + 5}"}]}]}
+}`)
+		environment := env.SetActiveProfiles("").WithPropertySource(source)
+
+		require.Equal(t, "value1", environment.Property("a.key1"))
+		require.Equal(t, "2.5", environment.Property("a.key2"))
+		require.Equal(t, "1000000", environment.Property("a.big"))
+		require.Equal(t, "true", environment.Property("enabled"))
+		require.Equal(t, "host2", environment.Property("servers[1]"))
+		require.Equal(t, "5", environment.Property("c.array[0].value"))
+		require.Equal(t, "7.5", environment.Property("c.array[1].sub-array[0].sub1"))
+	})
+
+	t.Run("should discover application.json and extension hints", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.json"), []byte(`{"name": "json", "url": "json"}`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.yaml"), []byte("name: yaml\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "generated"), []byte(`{"db": {"user": "sa"}}`), 0644))
+
+		environment := env.NewBuilder().
+			Args().
+			Environ().
+			Locations(dir+"/", dir+"/generated[.json]").
+			Build()
+
+		require.Equal(t, "yaml", environment.Property("name"))
+		require.Equal(t, "json", environment.Property("url"))
+		require.Equal(t, "sa", environment.Property("db.user"))
+	})
+}
@@ -0,0 +1,30 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/go-errr/go/err"
+)
+
+// LocationNotFoundError reports a config location or import that does not exist
+// and is not marked with the optional: prefix.
+type LocationNotFoundError struct {
+	err.RuntimeException
+	Location   string
+	DeclaredIn string
+}
+
+func NewLocationNotFoundError(location, declaredIn string) *LocationNotFoundError {
+	return &LocationNotFoundError{
+		RuntimeException: *err.NewRuntimeExceptionWith("", nil, err.StackTrace(1)),
+		Location:         location,
+		DeclaredIn:       declaredIn}
+}
+
+func (this *LocationNotFoundError) Error() string {
+	return fmt.Sprintf("Config location %s declared in %s does not exist. Use optional:%s if it may be missing", this.Location, this.DeclaredIn, this.Location)
+}
+
+func (this *LocationNotFoundError) Format(s fmt.State, verb rune) {
+	this.DefaultFormat(s, verb, this)
+}
@@ -0,0 +1,58 @@
+package env_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-external-config/go/env"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LocationNotFoundError(t *testing.T) {
+	t.Run("should fail on missing location", func(t *testing.T) {
+		dir := filepath.ToSlash(t.TempDir())
+
+		e := catch(func() {
+			env.NewBuilder().Args().Environ().Locations(dir + "/missing/").Build()
+		})
+
+		var notFound *env.LocationNotFoundError
+		require.True(t, errors.As(e, &notFound))
+		require.Equal(t, dir+"/missing/", notFound.Location)
+		require.Equal(t, "config.location", notFound.DeclaredIn)
+	})
+
+	t.Run("should fail on missing import naming the declaring file", func(t *testing.T) {
+		dir := filepath.ToSlash(t.TempDir())
+		require.NoError(t, os.WriteFile(dir+"/application.properties", []byte("config.import=db.properties\n"), 0644))
+
+		e := catch(func() {
+			env.NewBuilder().Args().Environ().Locations(dir + "/").Build()
+		})
+
+		var notFound *env.LocationNotFoundError
+		require.True(t, errors.As(e, &notFound))
+		require.Equal(t, dir+"/db.properties", notFound.Location)
+		require.Equal(t, dir+"/application.properties", notFound.DeclaredIn)
+		require.Contains(t, e.Error(), "declared in "+dir+"/application.properties")
+	})
+
+	t.Run("should skip optional locations and imports", func(t *testing.T) {
+		dir := filepath.ToSlash(t.TempDir())
+		require.NoError(t, os.WriteFile(dir+"/application.properties", []byte("name=app\nconfig.import=optional:db.properties,optional:configtree:secrets/\n"), 0644))
+		require.NoError(t, os.WriteFile(dir+"/app-dev.yaml", []byte("profile: dev\n"), 0644))
+
+		environment := env.NewBuilder().
+			Args().
+			Environ().
+			Locations(dir+"/", "optional:"+dir+"/missing/", "optional:"+dir+"/app.yaml").
+			Profiles("dev").
+			Build()
+
+		require.Equal(t, "app", environment.Property("name"))
+		require.Equal(t, "dev", environment.Property("profile"))
+		require.Equal(t, []string{dir + "/db.properties", dir + "/secrets", dir + "/missing/", dir + "/app.yaml"}, environment.SkippedLocations())
+	})
+}
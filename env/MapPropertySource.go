@@ -1,22 +1,31 @@
 package env
 
-import "github.com/go-jang/go/lang"
+import (
+	"github.com/go-jang/go/lang"
+	"github.com/go-jang/go/util/optional"
+)
 
+// Keys are matched relaxed, max-idle-conns, maxIdleConns and max_idle_conns are the same key, see canonicalForm
 type MapPropertySource struct {
-	name       string
-	properties map[string]string
+	name        string
+	properties  map[string]string
+	origins     map[string]Origin
+	relaxedKeys map[string]string // canonical form to the key as defined
+	exactKeys   bool              // environment variables follow envVarCanonicalForm instead
 }
 
 func MapPropertySourceOf(name string) *MapPropertySource {
 	return &MapPropertySource{
-		name:       name,
-		properties: make(map[string]string)}
+		name:        name,
+		properties:  make(map[string]string),
+		origins:     make(map[string]Origin),
+		relaxedKeys: make(map[string]string)}
 }
 
 func MapPropertySourceOfMap(name string, source map[string]string) *MapPropertySource {
-	return &MapPropertySource{
-		name:       name,
-		properties: source}
+	mapPropertySource := MapPropertySourceOf(name)
+	mapPropertySource.SetProperties(source)
+	return mapPropertySource
 }
 
 func (this *MapPropertySource) Name() string {
@@ -24,18 +33,19 @@ func (this *MapPropertySource) Name() string {
 }
 
 func (this *MapPropertySource) HasProperty(key string) bool {
-	_, ok := this.properties[key]
+	_, ok := this.properties[this.relaxedKey(key)]
 	return ok
 }
 
 func (this *MapPropertySource) Property(key string) string {
-	value, ok := this.properties[key]
+	value, ok := this.properties[this.relaxedKey(key)]
 	lang.Assert(ok, "%v has no %v", this.name, key)
 	return value
 }
 
 func (this *MapPropertySource) SetProperty(key string, value string) {
 	this.properties[key] = value
+	this.indexKey(key)
 }
 
 func (this *MapPropertySource) Properties() map[string]string {
@@ -44,9 +54,48 @@ func (this *MapPropertySource) Properties() map[string]string {
 
 func (this *MapPropertySource) SetProperties(properties map[string]string) {
 	this.properties = properties
+	this.relaxedKeys = make(map[string]string)
+	for key := range properties {
+		this.indexKey(key)
+	}
 }
 
 func (this *MapPropertySource) ContainsProperty(key string) bool {
-	_, ok := this.properties[key]
-	return ok
+	return this.HasProperty(key)
+}
+
+// key as defined in the source, like max-idle-conns for maxIdleConns, or the given key if none matches
+func (this *MapPropertySource) relaxedKey(key string) string {
+	if _, ok := this.properties[key]; ok || this.exactKeys {
+		return key
+	}
+	if relaxed, ok := this.relaxedKeys[canonicalForm(key)]; ok {
+		return relaxed
+	}
+	return key
+}
+
+// the first key in lexical order wins if several spellings of the same key are defined
+func (this *MapPropertySource) indexKey(key string) {
+	canonical := canonicalForm(key)
+	if existing, ok := this.relaxedKeys[canonical]; !ok || key < existing {
+		this.relaxedKeys[canonical] = key
+	}
+}
+
+// Origin set for the key, or source name and key if the key is defined without one
+func (this *MapPropertySource) Origin(key string) *optional.Optional[Origin] {
+	key = this.relaxedKey(key)
+	if origin, ok := this.origins[key]; ok {
+		return optional.OfValue(origin)
+	} else if this.HasProperty(key) {
+		return optional.OfValue(Origin{
+			Source: this.name,
+			Key:    key})
+	}
+	return optional.OfEmpty[Origin]()
+}
+
+func (this *MapPropertySource) SetOrigin(key string, origin Origin) {
+	this.origins[key] = origin
 }
@@ -23,4 +23,18 @@ func Test_MapPropertySource_Resolve(t *testing.T) {
 		require.Equal(t, "val1", environment.Property("prop4"))
 		require.Equal(t, "4", environment.Property("prop5"))
 	})
+
+	t.Run("should match keys relaxed", func(t *testing.T) {
+		source := env.MapPropertySourceOfMap("mapPropertySource", map[string]string{
+			"server.max-connections": "10",
+			"server.readTimeout":     "5s",
+			"servers[0].host_name":   "host1"})
+
+		require.True(t, source.HasProperty("server.maxConnections"))
+		require.Equal(t, "10", source.Property("server.max_connections"))
+		require.Equal(t, "5s", source.Property("server.read-timeout"))
+		require.Equal(t, "host1", source.Property("servers[0].hostName"))
+		require.Equal(t, "server.max-connections", source.Origin("SERVER.MAXCONNECTIONS").Value().Key)
+		require.False(t, source.HasProperty("server.max.connections"))
+	})
 }
@@ -0,0 +1,19 @@
+package env
+
+import "fmt"
+
+// Origin of a raw property value, see Environment.Origin
+type Origin struct {
+	Source string // property source name, like ./config/application.yaml or Environment variables
+	Key    string // key as defined in the source, like DB_URL for db.url
+	Line   int    // 1-based, 0 if unknown
+	Column int    // 1-based, 0 if unknown
+}
+
+// ./config/application.yaml:12:7 for files, Environment variables [DB_URL] otherwise
+func (this Origin) String() string {
+	if this.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d", this.Source, this.Line, this.Column)
+	}
+	return fmt.Sprintf("%s [%s]", this.Source, this.Key)
+}
@@ -0,0 +1,9 @@
+package env
+
+import "github.com/go-jang/go/util/optional"
+
+// Implemented by property sources that know where their values are defined, like YamlPropertySource.
+// Sources not implementing it are reported by name only.
+type OriginLookup interface {
+	Origin(key string) *optional.Optional[Origin]
+}
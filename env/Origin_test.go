@@ -0,0 +1,28 @@
+package env_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-external-config/go/env"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Environment_Origin(t *testing.T) {
+	t.Run("should report where the value comes from", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.yaml"), []byte("name: app\ndb:\n  url: yaml\n  user: yaml\n  password: base64:c2VjcmV0\n"), 0644))
+		environment := env.NewBuilder().
+			Args("--db.user=cli").
+			Environ("DB_URL=env").
+			Locations(dir + "/").
+			Build()
+
+		require.Equal(t, filepath.ToSlash(dir)+"/application.yaml:1:7", environment.Origin("name").Value().String())
+		require.Equal(t, "Environment variables [DB_URL]", environment.Origin("db.url").Value().String())
+		require.Equal(t, "Application parameters [db.user]", environment.Origin("db.user").Value().String())
+		require.Equal(t, filepath.ToSlash(dir)+"/application.yaml:5:13", environment.Origin("db.password").Value().String())
+		require.False(t, environment.Origin("missing").Present())
+	})
+}
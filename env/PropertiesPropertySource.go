@@ -1,6 +1,10 @@
 package env
 
-import "github.com/magiconair/properties"
+import (
+	"strings"
+
+	"github.com/magiconair/properties"
+)
 
 type PropertiesPropertySource struct {
 	MapPropertySource
@@ -10,6 +14,7 @@ func NewPropertiesPropertySource(name, content string) *PropertiesPropertySource
 	propertiesPropertySource := PropertiesPropertySource{
 		MapPropertySource: *MapPropertySourceOf(name)}
 	propertiesPropertySource.SetProperties(propertiesPropertySource.propertiesFrom(content))
+	propertiesPropertySource.trackOrigins(content)
 	return &propertiesPropertySource
 }
 
@@ -20,3 +25,41 @@ func (this *PropertiesPropertySource) propertiesFrom(content string) map[string]
 	}
 	return result
 }
+
+// records position of the value for every key, last definition wins the same way it does for the value
+func (this *PropertiesPropertySource) trackOrigins(content string) {
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		trimmed := strings.TrimLeft(line, " \t\f")
+		if len(trimmed) == 0 || trimmed[0] == '#' || trimmed[0] == '!' {
+			continue
+		}
+		lineNumber := i + 1
+		var key strings.Builder
+		j := len(line) - len(trimmed)
+		for ; j < len(line) && !strings.ContainsRune("=: \t\f", rune(line[j])); j++ {
+			if line[j] == '\\' && j+1 < len(line) {
+				j++
+			}
+			key.WriteByte(line[j])
+		}
+		j += len(line[j:]) - len(strings.TrimLeft(line[j:], " \t\f"))
+		if j < len(line) && (line[j] == '=' || line[j] == ':') {
+			j++
+			j += len(line[j:]) - len(strings.TrimLeft(line[j:], " \t\f"))
+		}
+		if this.HasProperty(key.String()) {
+			this.SetOrigin(key.String(), Origin{
+				Source: this.name,
+				Key:    key.String(),
+				Line:   lineNumber,
+				Column: j + 1})
+		}
+		// value continues on the next line if the line ends with an odd number of backslashes
+		for i+1 < len(lines) && (len(line)-len(strings.TrimRight(line, "\\")))%2 == 1 {
+			i++
+			line = strings.TrimRight(lines[i], "\r")
+		}
+	}
+}
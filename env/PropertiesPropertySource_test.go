@@ -25,3 +25,22 @@ func Test_PropertiesPropertySource_Resolve(t *testing.T) {
 		require.Equal(t, "4", environment.Property("prop5"))
 	})
 }
+
+func Test_PropertiesPropertySource_Origin(t *testing.T) {
+	t.Run("should track line and column of values", func(t *testing.T) {
+		source := env.NewPropertiesPropertySource("application.properties", `# comment
+prop1=val1
+  prop2 : multi \
+    line
+prop3 val3
+prop\=4=val4
+prop1=override
+`)
+
+		require.Equal(t, "application.properties:7:7", source.Origin("prop1").Value().String())
+		require.Equal(t, "application.properties:3:11", source.Origin("prop2").Value().String())
+		require.Equal(t, "application.properties:5:7", source.Origin("prop3").Value().String())
+		require.Equal(t, "application.properties:6:9", source.Origin("prop=4").Value().String())
+		require.False(t, source.Origin("line").Present())
+	})
+}
@@ -0,0 +1,32 @@
+package env
+
+// flag.Value of a property, set from a parsed flag.FlagSet to the command line arguments of the environment, see Environment.RegisterFlags
+type propertyFlag struct {
+	environment *Environment
+	key         string
+	value       string
+	boolFlag    bool
+}
+
+func (this *propertyFlag) String() string {
+	if this == nil {
+		return ""
+	}
+	return this.value
+}
+
+func (this *propertyFlag) Set(value string) error {
+	this.value = value
+	this.environment.paramsPropertySource.override(this.key, value)
+	return nil
+}
+
+// -debug without value stands for true if the property is boolean
+func (this *propertyFlag) IsBoolFlag() bool {
+	return this.boolFlag
+}
+
+// literally true or false, so retries=1 still takes a value
+func isBool(value string) bool {
+	return value == "true" || value == "false"
+}
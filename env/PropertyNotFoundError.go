@@ -0,0 +1,28 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/go-errr/go/err"
+)
+
+// PropertyNotFoundError reports a property key that is not defined by any property source
+// and has no default value.
+type PropertyNotFoundError struct {
+	err.RuntimeException
+	Key string
+}
+
+func NewPropertyNotFoundError(key string) *PropertyNotFoundError {
+	return &PropertyNotFoundError{
+		RuntimeException: *err.NewRuntimeExceptionWith("", nil, err.StackTrace(1)),
+		Key:              key}
+}
+
+func (this *PropertyNotFoundError) Error() string {
+	return fmt.Sprintf("No value present for %s", this.Key)
+}
+
+func (this *PropertyNotFoundError) Format(s fmt.State, verb rune) {
+	this.DefaultFormat(s, verb, this)
+}
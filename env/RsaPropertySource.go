@@ -46,6 +46,7 @@ const RSA_VALUE_PREFIX = "RSA:"
 //
 // | base64 - encodes the ciphertext to text format. It is safe to remove any line breaks.
 type RsaPropertySource struct {
+	environment *Environment
 }
 
 func NewRsaPropertySource() *RsaPropertySource {
@@ -57,7 +58,7 @@ func (this *RsaPropertySource) Name() string {
 }
 
 func (this *RsaPropertySource) HasProperty(key string) bool {
-	for _, source := range environment.PropertySources() {
+	for _, source := range this.environment.PropertySources() {
 		if source.Properties() != nil && source.HasProperty(key) {
 			return strings.HasPrefix(source.Property(key), RSA_VALUE_PREFIX)
 		}
@@ -66,10 +67,15 @@ func (this *RsaPropertySource) HasProperty(key string) bool {
 }
 
 func (this *RsaPropertySource) Property(key string) string {
-	for _, source := range environment.PropertySources() {
+	for _, source := range this.environment.PropertySources() {
 		if source.Properties() != nil && source.HasProperty(key) {
 			value := source.Property(key)[len(RSA_VALUE_PREFIX):]
-			rsaPrivateKeyPath := environment.Property("rsa.privateKey.path")
+			defer err.Catch(func(e any) {
+				conversionError := NewConversionError(RSA_VALUE_PREFIX+value, nil, e)
+				conversionError.Key, conversionError.Source = key, source.Name()
+				panic(conversionError)
+			})
+			rsaPrivateKeyPath := this.environment.Property("rsa.privateKey.path")
 			return this.decryptWithPrivateKey(key, value, rsaPrivateKeyPath)
 		}
 	}
@@ -77,7 +83,7 @@ func (this *RsaPropertySource) Property(key string) string {
 }
 
 func (this *RsaPropertySource) decryptWithPrivateKey(key, value, privateKeyPath string) string {
-	data, _ := os.ReadFile(privateKeyPath)
+	data := optional.OfCommaErr(os.ReadFile(privateKeyPath)).OrElsePanic("Cannot read private key from %s", privateKeyPath)
 	block, _ := pem.Decode(data)
 	lang.Assert(block != nil, "No PEM block found in %s", privateKeyPath)
 
@@ -90,11 +96,15 @@ func (this *RsaPropertySource) decryptWithPrivateKey(key, value, privateKeyPath
 	default:
 		panic(err.NewIllegalArgumentException(fmt.Sprintf("Unsupported key type %s", block.Type)))
 	}
-	cipher, _ := base64.StdEncoding.DecodeString(value)
+	cipher := optional.OfCommaErr(base64.StdEncoding.DecodeString(value)).OrElsePanic("Cannot decode %s=%s", key, value)
 	decrypted := optional.OfCommaErr(rsa.DecryptOAEP(sha256.New(), rand.Reader, priv, cipher, nil)).OrElsePanic("Cannot decrypt %s=%s", key, value)
 	return string(decrypted)
 }
 
+func (this *RsaPropertySource) SetEnvironment(environment *Environment) {
+	this.environment = environment
+}
+
 func (this *RsaPropertySource) Properties() map[string]string {
 	return nil
 }
@@ -0,0 +1,77 @@
+package env
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/go-errr/go/err"
+)
+
+// Property source of TOML document, flattened the same way as YAML:
+//
+//	[my]
+//	servers = ["dev.example.com", "another.example.com"]
+//
+// becomes
+//
+//	my.servers[0]=dev.example.com
+//	my.servers[1]=another.example.com
+//
+// Offset date-times are kept as RFC 3339, local date-times, dates and times as written.
+type TomlPropertySource struct {
+	MapPropertySource
+}
+
+func NewTomlPropertySource(name, toml string) *TomlPropertySource {
+	tomlPropertySource := TomlPropertySource{
+		MapPropertySource: *MapPropertySourceOf(name)}
+	tomlPropertySource.SetProperties(tomlPropertySource.propertiesFromToml(toml))
+	return &tomlPropertySource
+}
+
+func (this *TomlPropertySource) propertiesFromToml(tomlStr string) map[string]string {
+	properties := make(map[string]string)
+	parsedToml := make(map[string]any)
+	_, e := toml.Decode(tomlStr, &parsedToml)
+	if e != nil {
+		panic(err.NewRuntimeException(fmt.Sprintf("Unmarshalling failed: %v", e)))
+	}
+	flatten(this.normalize(parsedToml), "", properties)
+	return properties
+}
+
+// brings decoded values to the shape flatten understands
+func (this *TomlPropertySource) normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = this.normalize(item)
+		}
+		return v
+	case []map[string]any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = this.normalize(item)
+		}
+		return result
+	case []any:
+		for i, item := range v {
+			v[i] = this.normalize(item)
+		}
+		return v
+	case time.Time:
+		switch v.Location().String() {
+		case "datetime-local":
+			return v.Format("2006-01-02T15:04:05.999999999")
+		case "date-local":
+			return v.Format(time.DateOnly)
+		case "time-local":
+			return v.Format("15:04:05.999999999")
+		default:
+			return v.Format(time.RFC3339Nano)
+		}
+	default:
+		return v
+	}
+}
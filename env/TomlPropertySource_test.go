@@ -0,0 +1,82 @@
+package env_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-external-config/go/env"
+	"github.com/go-external-config/go/str"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TomlPropertySource_Resolve(t *testing.T) {
+	t.Run("should flatten tables and arrays", func(t *testing.T) {
+		source := env.NewTomlPropertySource("tomlPropertySource", `
+enabled = true
+servers = ["host1", "host2"]
+
+[a]
+key1 = "value1"
+key2 = 2.5
+big = 1_000_000
+
+[[c.array]]
+name = "element1"
+value = "#{3+2}"
+
+[[c.array]]
+sub-array = [{sub1 = "#{${a.key2} + 5}"}]
+`)
+		environment := env.SetActiveProfiles("").WithPropertySource(source)
+
+		require.Equal(t, "value1", environment.Property("a.key1"))
+		require.Equal(t, "2.5", environment.Property("a.key2"))
+		require.Equal(t, "1000000", environment.Property("a.big"))
+		require.Equal(t, "true", environment.Property("enabled"))
+		require.Equal(t, "host2", environment.Property("servers[1]"))
+		require.Equal(t, "element1", environment.Property("c.array[0].name"))
+		require.Equal(t, "5", environment.Property("c.array[0].value"))
+		require.Equal(t, "7.5", environment.Property("c.array[1].sub-array[0].sub1"))
+	})
+
+	t.Run("should keep datetimes readable", func(t *testing.T) {
+		source := env.NewTomlPropertySource("tomlPropertySource", `
+offset = 1979-05-27T07:32:00.5-07:00
+utc = 1979-05-27T07:32:00Z
+local-datetime = 1979-05-27T07:32:00
+local-date = 1979-05-27
+local-time = 07:32:00.999
+`)
+
+		require.Equal(t, "1979-05-27T07:32:00.5-07:00", source.Property("offset"))
+		require.Equal(t, "1979-05-27T07:32:00Z", source.Property("utc"))
+		require.Equal(t, "1979-05-27T07:32:00", source.Property("local-datetime"))
+		require.Equal(t, "1979-05-27", source.Property("local-date"))
+		require.Equal(t, "07:32:00.999", source.Property("local-time"))
+
+		require.True(t, time.Date(1979, 5, 27, 14, 32, 0, 500000000, time.UTC).Equal(str.Parse[time.Time](source.Property("offset"))))
+		require.Equal(t, time.Date(1979, 5, 27, 7, 32, 0, 0, time.Local), str.Parse[time.Time](source.Property("local-datetime")))
+		require.Equal(t, time.Date(1979, 5, 27, 0, 0, 0, 0, time.Local), str.Parse[time.Time](source.Property("local-date")))
+		require.Equal(t, time.Date(0, 1, 1, 7, 32, 0, 999000000, time.Local), str.Parse[time.Time](source.Property("local-time")))
+	})
+
+	t.Run("should discover application.toml and profile specific files", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.toml"), []byte("name = \"toml\"\nurl = \"toml\"\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application-dev.toml"), []byte("[db]\nuser = \"dev\"\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.yaml"), []byte("name: yaml\n"), 0644))
+
+		environment := env.NewBuilder().
+			Args().
+			Environ().
+			Locations(dir + "/").
+			Profiles("dev").
+			Build()
+
+		require.Equal(t, "yaml", environment.Property("name"))
+		require.Equal(t, "toml", environment.Property("url"))
+		require.Equal(t, "dev", environment.Property("db.user"))
+	})
+}
@@ -0,0 +1,34 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-errr/go/err"
+)
+
+// ValidationError reports all violations of validation rules found in a bound configuration at once,
+// so a misconfigured deployment fails with one complete message.
+type ValidationError struct {
+	err.RuntimeException
+	Violations []Violation
+}
+
+func NewValidationError(violations []Violation) *ValidationError {
+	return &ValidationError{
+		RuntimeException: *err.NewRuntimeExceptionWith("", nil, err.StackTrace(1)),
+		Violations:       violations}
+}
+
+func (this *ValidationError) Error() string {
+	var message strings.Builder
+	fmt.Fprintf(&message, "Configuration is invalid, %d violation(s):", len(this.Violations))
+	for _, violation := range this.Violations {
+		fmt.Fprintf(&message, "\n\t%s", violation)
+	}
+	return message.String()
+}
+
+func (this *ValidationError) Format(s fmt.State, verb rune) {
+	this.DefaultFormat(s, verb, this)
+}
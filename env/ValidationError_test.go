@@ -0,0 +1,131 @@
+package env_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-external-config/go/env"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ValidationError(t *testing.T) {
+	t.Run("should report all violations with key and origin", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args("--server.mode=staging").
+			Environ().
+			Build().
+			WithPropertySource(env.NewYamlPropertySource("application.yaml", `
+server:
+  port: 70000
+  name: x
+  password: hunter2
+  timeout: 100ms
+  tags: [a, b, c]
+  backends:
+    - url: http://backend
+      weight: 0
+`))
+
+		var server struct {
+			Port     int           `validate:"required,min=1,max=65535"`
+			URL      string        `validate:"required"`
+			Name     string        `validate:"min=2,pattern=^[a-z]{1,3}$"`
+			Password string        `validate:"min=12"`
+			Mode     string        `validate:"oneof=dev prod"`
+			Timeout  time.Duration `validate:"min=1s"`
+			Tags     []string      `validate:"max=2"`
+			Retries  *int          `validate:"min=1"`
+			Backends []Backend
+		}
+
+		e := catch(func() { env.ConfigurationPropertiesFrom(environment, "server", &server) })
+
+		var invalid *env.ValidationError
+		require.True(t, errors.As(e, &invalid))
+		require.Equal(t, []string{
+			"server.port must be at most 65535, was 70000 (application.yaml:3:9)",
+			"server.url is required",
+			"server.name must have length at least 2 (application.yaml:4:9)",
+			"server.password must have length at least 12 (application.yaml:5:13)",
+			"server.mode must be one of dev, prod (Application parameters [server.mode])",
+			"server.timeout must be at least 1s, was 100ms (application.yaml:6:12)",
+			"server.tags must have length at most 2 (application.yaml:7:10)",
+			"server.backends[0] weight must be positive (application.yaml:9:12)",
+		}, violations(invalid))
+		require.Contains(t, e.Error(), "Configuration is invalid, 8 violation(s):\n\tserver.port must be at most 65535")
+		require.NotContains(t, e.Error(), "hunter2")
+		require.Equal(t, "hunter2", invalid.Violations[3].Value)
+		require.Equal(t, 70000, server.Port)
+
+		_, e = env.ConfigurationPropertiesEFrom(environment, "server", &server)
+		require.True(t, errors.As(e, &invalid))
+	})
+
+	t.Run("should require properties to be provided, explicit false or 0 included", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args("--app.enabled=false", "--app.retries=0").
+			Environ().
+			Build()
+
+		var app struct {
+			Enabled bool          `validate:"required"`
+			Retries int           `validate:"required,max=3"`
+			Port    int           `validate:"required"`
+			Idle    time.Duration `default:"30s" validate:"required"`
+			Hosts   []string      `validate:"required"`
+		}
+
+		e := catch(func() { env.ConfigurationPropertiesFrom(environment, "app", &app) })
+
+		var invalid *env.ValidationError
+		require.True(t, errors.As(e, &invalid))
+		require.Equal(t, []string{
+			"app.port is required",
+			"app.hosts is required",
+		}, violations(invalid))
+		require.False(t, app.Enabled)
+	})
+
+	t.Run("should validate tagged values", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args("--db.port=0").
+			Environ().
+			Build()
+
+		var db struct {
+			Port int    `value:"${db.port}" validate:"required,min=1"`
+			Host string `value:"${db.host:}" validate:"required"`
+			Name string `value:"${db.name:main}" validate:"required"`
+		}
+
+		e := catch(func() { env.BindPropertiesFrom(environment, &db) })
+
+		var invalid *env.ValidationError
+		require.True(t, errors.As(e, &invalid))
+		require.Equal(t, []string{
+			"db.port must be at least 1, was 0 (Application parameters [db.port])",
+			"db.host is required",
+		}, violations(invalid))
+	})
+}
+
+type Backend struct {
+	URL    string `validate:"pattern=^https?://"`
+	Weight int
+}
+
+func (this *Backend) Validate() error {
+	if this.Weight <= 0 {
+		return errors.New("weight must be positive")
+	}
+	return nil
+}
+
+func violations(e *env.ValidationError) []string {
+	var result []string
+	for _, violation := range e.Violations {
+		result = append(result, violation.String())
+	}
+	return result
+}
@@ -0,0 +1,239 @@
+package env
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-errr/go/err"
+	"github.com/go-external-config/go/str"
+	"github.com/go-jang/go/lang"
+	refl "github.com/go-jang/go/lang/reflect"
+)
+
+// Bound configuration implementing it is checked right after binding, error is reported as violation along with ValidateTag ones
+type validatable interface {
+	Validate() error
+}
+
+// collects violations of bound configuration, see ValidateTag
+type validator struct {
+	environment *Environment
+	violations  []Violation
+}
+
+// fields are keyed the same way bindStruct binds them
+func (this *validator) validateStruct(prefix string, target reflect.Value) {
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		value := refl.Settable(target.Field(i))
+		name := tagKey(this.environment, field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && isStruct(field.Type) && len(name) == 0 {
+			this.validateNested(prefix, value)
+			continue
+		}
+		key := fieldKey(prefix, field, name)
+		if rules, ok := field.Tag.Lookup(ValidateTag); ok {
+			_, hasDefault := field.Tag.Lookup(DefaultTag)
+			this.validateValue(key, value, rules, this.defined(key) || hasDefault && !isMissing(value))
+		}
+		if field.IsExported() {
+			this.validateNested(key, value)
+		}
+	}
+	this.validateMethod(prefix, target)
+}
+
+// structs, including the ones in pointers, slices, arrays and maps
+func (this *validator) validateNested(key string, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if !value.IsNil() && value.Elem().Kind() == reflect.Struct {
+			this.validateStruct(key, value.Elem())
+		}
+	case reflect.Struct:
+		this.validateStruct(key, value)
+	case reflect.Slice, reflect.Array:
+		if isStruct(value.Type().Elem()) {
+			for i := 0; i < value.Len(); i++ {
+				this.validateNested(fmt.Sprintf("%s[%d]", key, i), value.Index(i))
+			}
+		}
+	case reflect.Map:
+		if isStruct(value.Type().Elem()) {
+			for iter := value.MapRange(); iter.Next(); {
+				// map values are not addressable, Validate() may have pointer receiver
+				entry := reflect.New(value.Type().Elem()).Elem()
+				entry.Set(iter.Value())
+				name := fmt.Sprint(iter.Key().Interface())
+				this.validateNested(key+lang.If(strings.ContainsAny(name, ".[]"), "["+name+"]", "."+name), entry)
+			}
+		}
+	}
+}
+
+func (this *validator) validateMethod(key string, target reflect.Value) {
+	var method validatable
+	if target.CanAddr() {
+		method, _ = target.Addr().Interface().(validatable)
+	} else {
+		method, _ = target.Interface().(validatable)
+	}
+	if method == nil {
+		return
+	}
+	if e := method.Validate(); e != nil {
+		this.violations = append(this.violations, Violation{
+			Key:     key,
+			Message: e.Error(),
+			Origin:  this.originOf(key)})
+	}
+}
+
+// rules of ValidateTag, defined tells the property is provided, explicit false or 0 included.
+// Zero values not provided are checked only by required
+func (this *validator) validateValue(key string, value reflect.Value, rules string, defined bool) {
+	for _, rule := range splitRules(rules) {
+		name, arg, _ := strings.Cut(rule, "=")
+		if name == "required" {
+			if !defined {
+				this.violate(key, nil, "is required")
+			}
+			continue
+		}
+		if !defined && isMissing(value) {
+			continue
+		}
+		actual := reflect.Indirect(value)
+		switch name {
+		case "min":
+			if compareToBound(actual, arg, name) < 0 {
+				this.violate(key, actual.Interface(), fmt.Sprintf("must %s at least %s", lang.If(hasLength(actual), "have length", "be"), arg))
+			}
+		case "max":
+			if compareToBound(actual, arg, name) > 0 {
+				this.violate(key, actual.Interface(), fmt.Sprintf("must %s at most %s", lang.If(hasLength(actual), "have length", "be"), arg))
+			}
+		case "oneof":
+			if !slices.Contains(strings.Fields(arg), fmt.Sprint(actual.Interface())) {
+				this.violate(key, actual.Interface(), fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(arg), ", ")))
+			}
+		case "pattern":
+			pattern, e := regexp.Compile(arg)
+			if e != nil {
+				panic(err.NewIllegalArgumentException(fmt.Sprintf("Invalid pattern of %s: %v", key, e)))
+			}
+			if !pattern.MatchString(fmt.Sprint(actual.Interface())) {
+				this.violate(key, actual.Interface(), fmt.Sprintf("must match %s", arg))
+			}
+		default:
+			panic(err.NewIllegalArgumentException(fmt.Sprintf("Unknown validation rule '%s' of %s", rule, key)))
+		}
+	}
+}
+
+func (this *validator) violate(key string, value any, message string) {
+	this.violations = append(this.violations, Violation{
+		Key:     key,
+		Value:   value,
+		Message: message,
+		Origin:  this.originOf(key)})
+}
+
+// property itself, list elements or nested properties are defined in any of the property sources
+func (this *validator) defined(key string) bool {
+	source, _ := this.environment.lookupPropertySource(key)
+	return source != nil || this.environment.hasProperties(key)
+}
+
+// lists and structs are not defined themselves, they are where their first element or property is
+func (this *validator) originOf(key string) Origin {
+	if origin := this.environment.Origin(key); origin.Present() {
+		return origin.Value()
+	}
+	if indexes := this.environment.propertyIndexes(key); len(indexes) > 0 {
+		return this.originOf(fmt.Sprintf("%s[%d]", key, indexes[0]))
+	}
+	if children := this.environment.propertyChildren(key); len(key) > 0 && len(children) > 0 {
+		child := children[slices.Min(slices.Collect(maps.Keys(children)))]
+		return this.originOf(key + lang.If(strings.HasPrefix(child, "["), "", ".") + child)
+	}
+	return Origin{}
+}
+
+// panics with all violations found
+func (this *validator) check() {
+	if len(this.violations) > 0 {
+		panic(NewValidationError(this.violations))
+	}
+}
+
+// required,min=1,pattern=^a,b$ is required, min=1 and pattern=^a,b$
+func splitRules(rules string) []string {
+	var pattern string
+	if i := strings.Index(","+rules, ",pattern="); i >= 0 {
+		rules, pattern = rules[:max(i-1, 0)], rules[i:]
+	}
+	var result []string
+	for _, rule := range strings.Split(rules, ",") {
+		if rule = strings.TrimSpace(rule); len(rule) > 0 {
+			result = append(result, rule)
+		}
+	}
+	if len(pattern) > 0 {
+		result = append(result, pattern)
+	}
+	return result
+}
+
+// nil, zero, or empty string, slice or map
+func isMissing(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+func hasLength(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+// numbers are compared by value with bound of the same type, so time.Duration takes min=1s, strings and collections by length
+func compareToBound(value reflect.Value, bound string, rule string) int {
+	defer err.Catch(func(e any) {
+		panic(err.NewIllegalArgumentException(fmt.Sprintf("Cannot apply %s=%s to %s: %v", rule, bound, value.Type(), e)))
+	})
+	if hasLength(value) {
+		length, e := strconv.Atoi(bound)
+		if e != nil {
+			panic(e)
+		}
+		return cmp.Compare(value.Len(), length)
+	}
+	limit := reflect.ValueOf(str.ParseOfType(bound, value.Type()))
+	switch {
+	case value.CanInt():
+		return cmp.Compare(value.Int(), limit.Int())
+	case value.CanUint():
+		return cmp.Compare(value.Uint(), limit.Uint())
+	case value.CanFloat():
+		return cmp.Compare(value.Float(), limit.Float())
+	default:
+		panic(fmt.Sprintf("%s is not a number", value.Type()))
+	}
+}
@@ -0,0 +1,34 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Violation of a validation rule by a bound property, see ValidateTag
+type Violation struct {
+	Key     string // property key, like server.port, empty for Validate() method of the target itself
+	Value   any    // value bound, nil for Validate() methods, printed only if it is a number or bool as strings may be secrets
+	Message string // like must be at most 65535
+	Origin  Origin // where the value is defined, zero if not defined
+}
+
+// server.port must be at most 65535, was 70000 (./config/application.yaml:3:9)
+func (this Violation) String() string {
+	message := this.Message
+	if len(this.Key) > 0 {
+		message = this.Key + " " + message
+	}
+	if this.Value != nil && isPrintable(reflect.ValueOf(this.Value)) {
+		message += fmt.Sprintf(", was %v", this.Value)
+	}
+	if len(this.Origin.Source) > 0 {
+		message += fmt.Sprintf(" (%s)", this.Origin)
+	}
+	return message
+}
+
+// numbers, like 70000 or 100ms, and bools, but not strings, lists or maps that may hold secrets
+func isPrintable(value reflect.Value) bool {
+	return value.Kind() == reflect.Bool || value.CanInt() || value.CanUint() || value.CanFloat()
+}
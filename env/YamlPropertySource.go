@@ -2,6 +2,8 @@ package env
 
 import (
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/go-errr/go/err"
 	"gopkg.in/yaml.v3"
@@ -11,25 +13,55 @@ type YamlPropertySource struct {
 	MapPropertySource
 }
 
+// Property source of the first document of the yaml, see NewYamlPropertySources for multi-document yaml
 func NewYamlPropertySource(name, yaml string) *YamlPropertySource {
+	return NewYamlPropertySources(name, yaml)[0]
+}
+
+// Property source per document of the yaml separated with ---, in order. At least one, empty if the yaml has no documents
+func NewYamlPropertySources(name, yamlStr string) []*YamlPropertySource {
+	var result []*YamlPropertySource
+	decoder := yaml.NewDecoder(strings.NewReader(yamlStr))
+	for {
+		var document yaml.Node
+		e := decoder.Decode(&document)
+		if e == io.EOF {
+			break
+		} else if e != nil {
+			panic(err.NewRuntimeException(fmt.Sprintf("Unmarshalling failed: %v", e)))
+		}
+		result = append(result, newYamlPropertySource(name, &document))
+	}
+	if len(result) == 0 {
+		result = append(result, newYamlPropertySource(name, &yaml.Node{}))
+	}
+	return result
+}
+
+func newYamlPropertySource(name string, document *yaml.Node) *YamlPropertySource {
 	yamlPropertySource := YamlPropertySource{
 		MapPropertySource: *MapPropertySourceOf(name)}
-	yamlPropertySource.SetProperties(yamlPropertySource.propertiesFromYaml(yaml))
+	yamlPropertySource.SetProperties(yamlPropertySource.propertiesFromYaml(document))
 	return &yamlPropertySource
 }
 
-func (this *YamlPropertySource) propertiesFromYaml(yamlStr string) map[string]string {
+func (this *YamlPropertySource) propertiesFromYaml(document *yaml.Node) map[string]string {
+	properties := make(map[string]string)
+	if len(document.Content) == 0 || document.Content[0].Tag == "!!null" {
+		return properties
+	}
 	var parsedYaml any
-	e := yaml.Unmarshal([]byte(yamlStr), &parsedYaml)
+	e := document.Decode(&parsedYaml)
 	if e != nil {
 		panic(err.NewRuntimeException(fmt.Sprintf("Unmarshalling failed: %v", e)))
 	}
-	properties := make(map[string]string)
-	this.flattenYaml(parsedYaml, "", properties)
+	flatten(parsedYaml, "", properties)
+	this.trackOrigins(document, "", true)
 	return properties
 }
 
-func (this *YamlPropertySource) flattenYaml(data any, prefix string, result map[string]string) {
+// nested maps to dotted keys, lists to [index] dereferencers
+func flatten(data any, prefix string, result map[string]string) {
 	switch v := data.(type) {
 	case map[string]any:
 		for key, value := range v {
@@ -37,14 +69,53 @@ func (this *YamlPropertySource) flattenYaml(data any, prefix string, result map[
 			if prefix != "" {
 				newPrefix = prefix + "." + key
 			}
-			this.flattenYaml(value, newPrefix, result)
+			flatten(value, newPrefix, result)
 		}
 	case []any:
 		for i, value := range v {
 			newPrefix := fmt.Sprintf("%s[%d]", prefix, i)
-			this.flattenYaml(value, newPrefix, result)
+			flatten(value, newPrefix, result)
 		}
 	default:
 		result[prefix] = fmt.Sprint(v)
 	}
 }
+
+// records position of the value node for every key flatten produces, keys defined explicitly win over merged ones (<<: *anchor)
+func (this *YamlPropertySource) trackOrigins(node *yaml.Node, prefix string, override bool) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, content := range node.Content {
+			this.trackOrigins(content, prefix, override)
+		}
+	case yaml.AliasNode:
+		this.trackOrigins(node.Alias, prefix, override)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" && value.Kind == yaml.SequenceNode {
+				for _, merged := range value.Content {
+					this.trackOrigins(merged, prefix, false)
+				}
+			} else if key.Value == "<<" {
+				this.trackOrigins(value, prefix, false)
+			} else if prefix == "" {
+				this.trackOrigins(value, key.Value, override)
+			} else {
+				this.trackOrigins(value, prefix+"."+key.Value, override)
+			}
+		}
+	case yaml.SequenceNode:
+		for i, value := range node.Content {
+			this.trackOrigins(value, fmt.Sprintf("%s[%d]", prefix, i), override)
+		}
+	default:
+		if _, tracked := this.origins[prefix]; override || !tracked {
+			this.SetOrigin(prefix, Origin{
+				Source: this.name,
+				Key:    prefix,
+				Line:   node.Line,
+				Column: node.Column})
+		}
+	}
+}
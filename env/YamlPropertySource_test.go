@@ -1,6 +1,8 @@
 package env_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/go-external-config/go/env"
@@ -37,3 +39,70 @@ c:
 		require.Equal(t, "7.5", environment.Property("c.array[1].sub-array[0].sub1"))
 	})
 }
+
+func Test_YamlPropertySource_Origin(t *testing.T) {
+	t.Run("should track line and column of values", func(t *testing.T) {
+		source := env.NewYamlPropertySource("application.yaml", `defaults: &defaults
+  timeout: 5
+db:
+  <<: *defaults
+  url: jdbc:h2
+  hosts:
+    - host1
+    - host2
+`)
+
+		require.Equal(t, "application.yaml:5:8", source.Origin("db.url").Value().String())
+		require.Equal(t, "application.yaml:8:7", source.Origin("db.hosts[1]").Value().String())
+		require.Equal(t, "application.yaml:2:12", source.Origin("db.timeout").Value().String())
+		require.Equal(t, env.Origin{Source: "application.yaml", Key: "db.url", Line: 5, Column: 8}, source.Origin("db.url").Value())
+		require.False(t, source.Origin("db.missing").Present())
+	})
+}
+
+func Test_YamlPropertySource_MultiDocument(t *testing.T) {
+	t.Run("should load every document in order", func(t *testing.T) {
+		sources := env.NewYamlPropertySources("application.yaml", `name: first
+---
+name: second
+other: value
+---
+`)
+
+		require.Len(t, sources, 3)
+		require.Equal(t, "first", sources[0].Property("name"))
+		require.Equal(t, "second", sources[1].Property("name"))
+		require.Equal(t, "application.yaml:4:8", sources[1].Origin("other").Value().String())
+		require.Empty(t, sources[2].Properties())
+		require.Equal(t, "first", env.NewYamlPropertySource("application.yaml", "name: first\n---\nname: second\n").Property("name"))
+	})
+
+	t.Run("should activate documents on profile", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "application.yaml"), []byte(`name: default
+url: default
+---
+config.activate.on-profile: prod & !cloud
+name: prod
+---
+config:
+  activate:
+    on-profile: cloud
+url: cloud
+---
+config.activate.on-profile: dev | test
+name: dev
+`), 0644))
+
+		prod := env.NewBuilder().Args().Environ().Locations(dir + "/").Profiles("prod").Build()
+		cloud := env.NewBuilder().Args().Environ().Locations(dir + "/").Profiles("prod,cloud").Build()
+		test := env.NewBuilder().Args().Environ().Locations(dir + "/").Profiles("test").Build()
+
+		require.Equal(t, "prod", prod.Property("name"))
+		require.Equal(t, "default", prod.Property("url"))
+		require.Equal(t, "default", cloud.Property("name"))
+		require.Equal(t, "cloud", cloud.Property("url"))
+		require.Equal(t, "dev", test.Property("name"))
+		require.Equal(t, "default", test.Property("url"))
+	})
+}
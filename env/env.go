@@ -1,9 +1,12 @@
 package env
 
 import (
+	"flag"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/go-errr/go/err"
@@ -15,56 +18,346 @@ import (
 
 const ValueTag = "value"
 
+// Property key of the field in ConfigurationProperties, relative to the prefix, "-" to skip the field
+//
+//	Addr string `config:"address"`
+const ConfigTag = "config"
+
+// Value of the field in ConfigurationProperties if the property is not defined and the field is zero, placeholders resolved
+//
+//	Timeout time.Duration `default:"30s"`
+const DefaultTag = "default"
+
+// Rules checked right after binding, separated with commas, pattern goes last as it may contain commas:
+//
+//	Port int `validate:"required,min=1,max=65535"`
+//	Mode string `validate:"oneof=dev prod,pattern=^[a-z]+$"`
+const ValidateTag = "validate"
+
+var placeholderPattern = regexp.MustCompile(`^\$\{([^${}:]+)\}$`)
+
+// ${db.port} or ${db.port:5432}
+var placeholderKeyPattern = regexp.MustCompile(`^\$\{([^${}:]+)(:[^${}]*)?\}$`)
+
 // Expression to evaluate against environment properties
 //
 //	require.Equal(t, "value", env.Value[string]("${key:default}"))
-//	require.Equal(t, []string{"host1", "host2", "host3"}, env.Value[[]string]("#{split('${servers}', ',')}"))
+//	require.Equal(t, []string{"host1", "host2", "host3"}, env.Value[[]string]("${servers}"))
 func Value[T any](expression string) T {
-	return convertAs[T](Instance().ResolveRequiredPlaceholders(expression))
+	return ValueFrom[T](Instance(), expression)
+}
+
+// Same as Value, but evaluated against the given environment, see env.NewBuilder()
+func ValueFrom[T any](environment *Environment, expression string) T {
+	return resolveExpressionAs(environment, expression, lang.TypeOf[T]()).(T)
+}
+
+// Exact ${key} is resolved as the property, so ${datasources} collects the keys nested under datasources,
+// ${servers} the elements of servers[0], servers[1], and literal values, like single quoted ones of .env files, stay as they are
+func resolveExpressionAs(environment *Environment, expression string, t reflect.Type) any {
+	if match := placeholderPattern.FindStringSubmatch(expression); match != nil {
+		return environment.resolvePropertyAs(match[1], t)
+	}
+	return convertAsType(environment.ResolveRequiredPlaceholders(expression), t)
+}
+
+// Same as Value, but returns *PropertyNotFoundError, *ConversionError or *ExpressionError instead of panicking
+//
+//	timeout, e := env.ValueE[time.Duration]("#{${timeout.seconds} * time.Second}")
+func ValueE[T any](expression string) (value T, e error) {
+	return ValueEFrom[T](Instance(), expression)
 }
 
-// Binds properties with the given prefix to the target struct using field names
+// Same as ValueE, but evaluated against the given environment, see env.NewBuilder()
+func ValueEFrom[T any](environment *Environment, expression string) (value T, e error) {
+	defer err.Catch(func(cause any) {
+		e = asError(cause)
+	})
+	return ValueFrom[T](environment, expression), nil
+}
+
+// Resolves property with the given key and converts it to T.
+// Returns *PropertyNotFoundError if the key is not defined, *ConversionError or *ExpressionError if it cannot be resolved
+//
+//	port, e := env.Lookup[int]("server.port")
+//	if errors.As(e, new(*env.PropertyNotFoundError)) {
+//		port = 8080
+//	}
+func Lookup[T any](key string) (value T, e error) {
+	return LookupFrom[T](Instance(), key)
+}
+
+// Same as Lookup, but resolved from the given environment, see env.NewBuilder()
+func LookupFrom[T any](environment *Environment, key string) (value T, e error) {
+	defer err.Catch(func(cause any) {
+		e = asError(cause)
+	})
+	return environment.resolvePropertyAs(key, lang.TypeOf[T]()).(T), nil
+}
+
+// Binds properties with the given prefix to the target struct using field names.
+// Keys are matched relaxed, MaxIdleConns field is bound from max-idle-conns, maxIdleConns or max_idle_conns.
+// Nested structs are bound recursively, db.pool.maxSize to DB.Pool.MaxSize, nil pointers to structs are allocated
+// if any of the nested properties is defined, and fields of embedded structs are bound as if declared by the outer struct.
+// Keys and fallback values may be given with tags, see ConfigTag and DefaultTag. Bound struct is validated, see ValidateTag.
 func ConfigurationProperties[T any](prefix string, target *T) *T {
-	targetType := reflect.TypeOf(target).Elem()
-	targetValue := reflect.ValueOf(target).Elem()
-	for i := 0; i < targetType.NumField(); i++ {
-		reflectField := targetType.Field(i)
-		rawValue := Instance().lookupRawProperty(fmt.Sprintf("%s.%s", prefix, reflectField.Name))
-		if !rawValue.Present() && unicode.IsUpper(rune(reflectField.Name[0])) {
-			decapitalizedName := strings.ToLower(reflectField.Name[:1]) + reflectField.Name[1:]
-			rawValue = Instance().lookupRawProperty(fmt.Sprintf("%s.%s", prefix, decapitalizedName))
+	return ConfigurationPropertiesFrom(Instance(), prefix, target)
+}
+
+// Same as ConfigurationProperties, but bound from the given environment, see env.NewBuilder()
+func ConfigurationPropertiesFrom[T any](environment *Environment, prefix string, target *T) *T {
+	bindStruct(environment, prefix, reflect.ValueOf(target).Elem())
+	validator := validator{environment: environment}
+	validator.validateStruct(prefix, reflect.ValueOf(target).Elem())
+	validator.check()
+	return target
+}
+
+// reports whether any field is bound
+func bindStruct(environment *Environment, prefix string, target reflect.Value) bool {
+	bound := false
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		value := refl.Settable(target.Field(i))
+		name := tagKey(environment, field)
+		if name == "-" {
+			continue
 		}
-		if !rawValue.Present() {
+		if field.Anonymous && isStruct(field.Type) && len(name) == 0 {
+			bound = bindNested(environment, prefix, value) || bound
 			continue
 		}
-		value := Instance().ResolveRequiredPlaceholders(rawValue.Value())
-		targetFieldValue := targetValue.FieldByName(reflectField.Name)
-		converted := convertAsType(value, targetFieldValue.Type())
-		refl.Settable(targetFieldValue).Set(reflect.ValueOf(converted))
+		key := fieldKey(prefix, field, name)
+		if bindValue(environment, key, value) {
+			bound = true
+		} else if defaultValue, ok := field.Tag.Lookup(DefaultTag); ok && isMissing(value) {
+			bindDefault(environment, key, defaultValue, value)
+		}
 	}
-	return target
+	return bound
 }
 
-// Binds properties to the target struct using field tags.
+// name given by the first of the key tags having one, config and then the ones of Builder.KeyTags, like address of `json:"address,omitempty"`
+func tagKey(environment *Environment, field reflect.StructField) string {
+	for _, tag := range environment.keyTags {
+		if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); len(name) > 0 {
+			return name
+		}
+	}
+	return ""
+}
+
+// relaxed, so MaxIdleConns field also matches max-idle-conns and max_idle_conns.
+// Leading acronym is lower case, URL is url and URLPath is urlPath
+func fieldKey(prefix string, field reflect.StructField, name string) string {
+	key := name
+	if len(key) == 0 {
+		upper := len(field.Name) - len(strings.TrimLeftFunc(field.Name, unicode.IsUpper))
+		if upper > 1 && upper < len(field.Name) {
+			upper--
+		}
+		key = strings.ToLower(field.Name[:upper]) + field.Name[upper:]
+	}
+	return lang.If(len(prefix) == 0, key, prefix+"."+key)
+}
+
+// value of DefaultTag, not counted as bound, so pointers to structs having only defaults stay nil
+func bindDefault(environment *Environment, key, defaultValue string, value reflect.Value) {
+	defer err.Catch(func(e any) {
+		panic(withProperty(e, key, DefaultTag+" tag"))
+	})
+	t := value.Type()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	converted := reflect.ValueOf(convertAsType(environment.ResolveRequiredPlaceholders(defaultValue), t))
+	if value.Kind() == reflect.Pointer {
+		target := reflect.New(t)
+		target.Elem().Set(converted)
+		converted = target
+	}
+	value.Set(converted)
+}
+
+// struct or pointer to struct, allocated if any of the nested properties is defined
+func bindNested(environment *Environment, prefix string, value reflect.Value) bool {
+	if value.Kind() == reflect.Struct {
+		return bindStruct(environment, prefix, value)
+	}
+	if !environment.hasProperties(prefix) {
+		return false
+	}
+	target := value
+	if value.IsNil() {
+		target = reflect.New(value.Type().Elem())
+	}
+	if bindStruct(environment, prefix, target.Elem()) {
+		value.Set(target)
+		return true
+	}
+	return false
+}
+
+func bindValue(environment *Environment, key string, value reflect.Value) bool {
+	if value.Kind() == reflect.Map {
+		return bindMap(environment, key, value)
+	}
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		if indexes := environment.propertyIndexes(key); len(indexes) > 0 {
+			return bindList(environment, key, value, indexes)
+		}
+	}
+	source, _ := environment.lookupPropertySource(key)
+	if source == nil && isStruct(value.Type()) {
+		return bindNested(environment, key, value)
+	}
+	if source == nil {
+		return false
+	}
+	if value.Kind() == reflect.Pointer {
+		target := reflect.New(value.Type().Elem())
+		target.Elem().Set(reflect.ValueOf(environment.resolvePropertyAs(key, target.Elem().Type())))
+		value.Set(target)
+		return true
+	}
+	value.Set(reflect.ValueOf(environment.resolvePropertyAs(key, value.Type())))
+	return true
+}
+
+// slice or array from indexed keys, my.servers[0] and my.servers[1], elements not defined are left zero.
+// Slice is replaced as a whole, array keeps elements beyond the ones bound
+func bindList(environment *Environment, key string, value reflect.Value, indexes []int) bool {
+	list := value
+	if value.Kind() == reflect.Slice {
+		length := indexes[len(indexes)-1] + 1
+		list = reflect.MakeSlice(value.Type(), length, length)
+	}
+	for _, i := range indexes {
+		lang.Assert(i < list.Len(), "Cannot bind %s[%d] to %s", key, i, value.Type())
+		bindValue(environment, fmt.Sprintf("%s[%d]", key, i), list.Index(i))
+	}
+	if value.Kind() == reflect.Slice {
+		value.Set(list)
+	}
+	return true
+}
+
+// map from the immediate children of the prefix, datasources.primary.url and datasources.replica.url bind primary and replica.
+// Existing entries are kept unless overridden, the map itself is replaced rather than modified
+func bindMap(environment *Environment, prefix string, value reflect.Value) bool {
+	result := reflect.MakeMap(value.Type())
+	if !value.IsNil() {
+		for iter := value.MapRange(); iter.Next(); {
+			result.SetMapIndex(iter.Key(), iter.Value())
+		}
+	}
+	bound := false
+	for _, child := range environment.propertyChildren(prefix) {
+		element := reflect.New(value.Type().Elem()).Elem()
+		if !bindValue(environment, prefix+lang.If(strings.HasPrefix(child, "["), "", ".")+child, element) {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(child, "["), "]")
+		result.SetMapIndex(reflect.ValueOf(convertAsType(name, value.Type().Key())), element)
+		bound = true
+	}
+	if bound {
+		value.Set(result)
+	}
+	return bound
+}
+
+func isStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct
+}
+
+// Same as ConfigurationProperties, but returns *PropertyNotFoundError, *ConversionError, *ExpressionError or *ValidationError instead of panicking.
+// Target is returned along with the error, having the fields bound before the failure, all of them for *ValidationError.
+func ConfigurationPropertiesE[T any](prefix string, target *T) (result *T, e error) {
+	return ConfigurationPropertiesEFrom(Instance(), prefix, target)
+}
+
+// Same as ConfigurationPropertiesE, but bound from the given environment, see env.NewBuilder()
+func ConfigurationPropertiesEFrom[T any](environment *Environment, prefix string, target *T) (result *T, e error) {
+	defer err.Catch(func(cause any) {
+		result, e = target, asError(cause)
+	})
+	return ConfigurationPropertiesFrom(environment, prefix, target), nil
+}
+
+// Binds properties to the target struct using field tags, then validates it, see ValidateTag.
 func BindProperties[T any](target *T) *T {
 	BindPropertiesAny(target)
 	return target
 }
 
+// Same as BindProperties, but bound from the given environment, see env.NewBuilder()
+func BindPropertiesFrom[T any](environment *Environment, target *T) *T {
+	bindProperties(environment, target)
+	return target
+}
+
 // Binds properties to the target struct using field tags.
 func BindPropertiesAny(target any) any {
+	return bindProperties(Instance(), target)
+}
+
+func bindProperties(environment *Environment, target any) any {
 	refl.ForEachTaggedField(target, ValueTag, func(field refl.Field) {
 		defer err.Catch(func(e any) {
 			panic(err.NewRuntimeExceptionFrom(fmt.Sprintf("Cannot bind configuration value '%s' to field '%s'", field.TagValue, field.Field.Name), e))
 		})
-		value := Instance().ResolveRequiredPlaceholders(field.TagValue)
-		converted := convertAsType(value, field.Type)
-		field.Value.Set(reflect.ValueOf(converted))
+		field.Value.Set(reflect.ValueOf(resolveExpressionAs(environment, field.TagValue, field.Type)))
 	})
-
+	validator := validator{environment: environment}
+	refl.ForEachTaggedField(target, ValidateTag, func(field refl.Field) {
+		// ${db.port} is provided if defined, ${db.port:5432} also by non-zero default, other expressions if the value is not zero
+		if match := placeholderKeyPattern.FindStringSubmatch(field.Field.Tag.Get(ValueTag)); match != nil {
+			validator.validateValue(match[1], field.Value, field.TagValue, validator.defined(match[1]) || len(match[2]) > 0 && !isMissing(field.Value))
+		} else {
+			validator.validateValue(field.Field.Name, field.Value, field.TagValue, !isMissing(field.Value))
+		}
+	})
+	validator.validateMethod("", reflect.ValueOf(target).Elem())
+	validator.check()
 	return target
 }
 
+// Same as BindProperties, but returns an error instead of panicking.
+// The error names the field and wraps *PropertyNotFoundError, *ConversionError or *ExpressionError, or is *ValidationError, use errors.As to inspect.
+// Target is returned along with the error, having the fields bound before the failure, all of them for *ValidationError.
+func BindPropertiesE[T any](target *T) (result *T, e error) {
+	return BindPropertiesEFrom(Instance(), target)
+}
+
+// Same as BindPropertiesE, but bound from the given environment, see env.NewBuilder()
+func BindPropertiesEFrom[T any](environment *Environment, target *T) (result *T, e error) {
+	defer err.Catch(func(cause any) {
+		result, e = target, asError(cause)
+	})
+	return BindPropertiesFrom(environment, target), nil
+}
+
+// Poll configuration files of the environment for changes every interval, see Environment.Watch
+func Watch(interval time.Duration) func() {
+	return Instance().Watch(interval)
+}
+
+// Subscribe to changes of properties with the given prefix detected by Watch, see Environment.OnChange
+func OnChange(prefix string, listener func(ChangeEvent)) *Environment {
+	return Instance().OnChange(prefix, listener)
+}
+
+// Command line arguments that are not options, like file names, see Environment.PositionalArgs
+func PositionalArgs() []string {
+	return Instance().PositionalArgs()
+}
+
+// Defines flags for properties with the given prefixes, see Environment.RegisterFlags
+func RegisterFlags(flags *flag.FlagSet, prefixes ...string) *flag.FlagSet {
+	return Instance().RegisterFlags(flags, prefixes...)
+}
+
 // last wins
 func ActiveProfiles() []string {
 	return Instance().activeProfiles
@@ -85,17 +378,17 @@ func MatchesProfiles(profiles ...string) bool {
 func SetActiveProfiles(profiles string) *Environment {
 	var result *Environment
 	concurrent.Synchronized(&environmentMu, func() {
-		if environment != nil && "default,"+profiles == strings.Join(environment.ActiveProfiles(), ",") {
+		if environment != nil && len(profiles) > 0 && profiles == strings.Join(environment.activatedProfiles, ",") {
 			result = environment
 			return
 		}
 
 		previous := environment
-		environment = newEnvironment(profiles)
+		environment = NewBuilder().Profiles(profiles).Build()
 
 		// keep custom property preprocessors
 		if previous != nil {
-			for _, source := range previous.propertySources {
+			for _, source := range previous.sources() {
 				if source.Properties() == nil {
 					environment.WithPropertySource(source)
 				}
@@ -106,27 +399,78 @@ func SetActiveProfiles(profiles string) *Environment {
 	return result
 }
 
+// Converter to use for properties bound to T, consulted before the built-in conversions
+// by Value, ConfigurationProperties and BindProperties, slices and maps of T included.
+// Types implementing encoding.TextUnmarshaler, json.Unmarshaler or flag.Value need no converter.
+//
+//	env.RegisterConverter(func(value string) (Currency, error) {
+//		return CurrencyOf(value)
+//	})
+func RegisterConverter[T any](converter func(string) (T, error)) {
+	str.RegisterParser(lang.TypeOf[T](), func(value string) (any, error) {
+		return converter(value)
+	})
+}
+
 func convertAs[T any](value any) T {
 	return convertAsType(value, lang.TypeOf[T]()).(T)
 }
 
 func convertAsType(value any, t reflect.Type) any {
+	defer err.Catch(func(e any) {
+		if _, ok := e.(*ConversionError); ok {
+			panic(e)
+		}
+		panic(NewConversionError(value, t, e))
+	})
 	switch t.Kind() {
+	case reflect.Interface:
+		lang.Assert(reflect.TypeOf(value).Implements(t), "%T does not implement %s", value, t)
+		return value
 	case reflect.String:
-		switch v := value.(type) {
-		case string:
-			return v
-		default:
-			return fmt.Sprint(v)
-		}
+		// named string types may have converter or UnmarshalText
+		return str.ParseOfType(fmt.Sprint(value), t)
 	default:
 		switch v := value.(type) {
 		case string:
 			return str.ParseOfType(v, t)
 		default:
 			val := reflect.ValueOf(value)
-			lang.Assert(val.Type().ConvertibleTo(t), "Cannot convert %s %v to %v", val.Type().Name(), value, t.Name())
+			if val.Kind() == reflect.Map && t.Kind() == reflect.Map && !val.Type().ConvertibleTo(t) {
+				// map[string]any of expression, #{ {'a': 1} }
+				result := reflect.MakeMap(t)
+				for iter := val.MapRange(); iter.Next(); {
+					result.SetMapIndex(reflect.ValueOf(convertAsType(iter.Key().Interface(), t.Key())),
+						reflect.ValueOf(convertAsType(iter.Value().Interface(), t.Elem())))
+				}
+				return result.Interface()
+			}
+			if !val.Type().ConvertibleTo(t) {
+				panic(NewConversionError(value, t, nil))
+			}
 			return val.Convert(t).Interface()
 		}
 	}
 }
+
+// fills in the property the error occurred for, unless already known
+func withProperty(e any, key, source string) any {
+	switch v := e.(type) {
+	case *ConversionError:
+		if len(v.Key) == 0 {
+			v.Key, v.Source = key, source
+		}
+	case *ExpressionError:
+		if len(v.Key) == 0 {
+			v.Key, v.Source = key, source
+		}
+	}
+	return e
+}
+
+func asError(e any) error {
+	if v, ok := e.(error); ok {
+		return v
+	}
+	return err.NewRuntimeException(fmt.Sprint(e))
+}
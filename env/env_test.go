@@ -1,6 +1,14 @@
 package env_test
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -75,6 +83,360 @@ func Test_Env_ConfigurationProperties(t *testing.T) {
 		require.Equal(t, 0, db.port2)
 		require.Equal(t, Port(333), db.port3)
 	})
+
+	t.Run("should bind kebab-case, camelCase and snake_case keys", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args("--pool.max_idle_time=30").
+			Environ("POOL_MAXOPENCONNS=20").
+			Build().
+			WithPropertySource(env.NewYamlPropertySource("application.yaml", `
+pool:
+  max-idle-conns: 5
+  max-open-conns: 10
+  conn_max_lifetime: 60
+  healthCheck: true
+`))
+
+		var pool struct {
+			MaxIdleConns    int
+			MaxOpenConns    int
+			ConnMaxLifetime int
+			MaxIdleTime     int
+			HealthCheck     bool
+		}
+
+		env.ConfigurationPropertiesFrom(environment, "pool", &pool)
+
+		require.Equal(t, 5, pool.MaxIdleConns)
+		require.Equal(t, 20, pool.MaxOpenConns)
+		require.Equal(t, 60, pool.ConnMaxLifetime)
+		require.Equal(t, 30, pool.MaxIdleTime)
+		require.True(t, pool.HealthCheck)
+		require.Equal(t, "5", environment.Property("pool.max_idle_conns"))
+		require.Equal(t, "application.yaml:3:19", environment.Origin("pool.maxIdleConns").Value().String())
+	})
+
+	t.Run("should bind environment variables with underscores between words", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args().
+			Environ("POOL_MAX_IDLE_CONNS=9", "MYAPP_POOL_MAX_OPEN_CONNS=20", "POOL_CONN_MAX_LIFETIME=90").
+			EnvPrefix("MYAPP").
+			Build().
+			WithPropertySource(env.NewYamlPropertySource("application.yaml", `
+pool:
+  max-idle-conns: 5
+  conn-max-lifetime: 60
+`))
+
+		var pool struct {
+			MaxIdleConns    int
+			MaxOpenConns    int
+			ConnMaxLifetime int
+		}
+
+		env.ConfigurationPropertiesFrom(environment, "pool", &pool)
+
+		// unprefixed variables are ignored when the prefix is set
+		require.Equal(t, 5, pool.MaxIdleConns)
+		require.Equal(t, 20, pool.MaxOpenConns)
+		require.Equal(t, 60, pool.ConnMaxLifetime)
+		require.Equal(t, "20", environment.Property("pool.max_open_conns"))
+
+		environment = env.NewBuilder().
+			Args().
+			Environ("POOL_MAX_IDLE_CONNS=9").
+			Build().
+			WithPropertySource(env.MapPropertySourceOfMap("application.properties", map[string]string{
+				"pool.max-idle-conns": "5"}))
+
+		env.ConfigurationPropertiesFrom(environment, "pool", &pool)
+
+		require.Equal(t, 9, pool.MaxIdleConns)
+		require.Equal(t, "9", env.ValueFrom[string](environment, "${pool.max_idle_conns}"))
+		require.Equal(t, "Environment variables [POOL_MAX_IDLE_CONNS]", environment.Origin("pool.maxIdleConns").Value().String())
+	})
+
+	t.Run("should bind nested, pointer and embedded structs", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args().
+			Environ("APP_DB_POOL_MINSIZE=2").
+			Build().
+			WithPropertySource(env.NewYamlPropertySource("application.yaml", `
+app:
+  name: myapp
+  timeout: 30
+  db:
+    url: jdbc
+    pool:
+      max-size: 10
+  cache:
+    ttl: 60
+`))
+
+		type Pool struct {
+			MinSize int
+			MaxSize int
+		}
+		type Common struct {
+			Name string
+		}
+		type Cache struct {
+			Ttl int
+		}
+		var config struct {
+			Common
+			*Timeouts
+			DB struct {
+				URL  string
+				Pool *Pool
+			}
+			Cache   *Cache
+			Metrics *Cache
+			Retries *int
+		}
+
+		env.ConfigurationPropertiesFrom(environment, "app", &config)
+
+		require.Equal(t, "myapp", config.Name)
+		require.Equal(t, 30, config.Timeout)
+		require.Equal(t, "jdbc", config.DB.URL)
+		require.Equal(t, &Pool{MinSize: 2, MaxSize: 10}, config.DB.Pool)
+		require.Equal(t, &Cache{Ttl: 60}, config.Cache)
+		require.Nil(t, config.Metrics)
+		require.Nil(t, config.Retries)
+	})
+
+	t.Run("should bind slices and arrays from indexed keys and delimited values", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args("--app.tags=a, b").
+			Environ("APP_SERVERS_1_=override.example.com", "APP_SERVERS_2_=third.example.com", "APP_PORTS=80,443").
+			Build().
+			WithPropertySource(env.NewYamlPropertySource("application.yaml", `
+app:
+  servers:
+    - dev.example.com
+    - another.example.com
+  ports: [8080]
+  tags: [x, y, z]
+  users:
+    - name: admin
+      roles: [read, write]
+    - name: guest
+  weights: 1,2,3
+`))
+
+		type User struct {
+			Name  string
+			Roles []string
+		}
+		var config struct {
+			Servers []string
+			Ports   []int
+			Tags    []string
+			Users   []User
+			Weights [4]float64
+			Empty   []string
+		}
+
+		env.ConfigurationPropertiesFrom(environment, "app", &config)
+
+		require.Equal(t, []string{"dev.example.com", "override.example.com", "third.example.com"}, config.Servers)
+		require.Equal(t, []int{80, 443}, config.Ports)
+		require.Equal(t, []string{"a", "b"}, config.Tags)
+		require.Equal(t, []User{{Name: "admin", Roles: []string{"read", "write"}}, {Name: "guest"}}, config.Users)
+		require.Equal(t, [4]float64{1, 2, 3, 0}, config.Weights)
+		require.Nil(t, config.Empty)
+		require.Equal(t, []string{"dev.example.com", "override.example.com", "third.example.com"}, env.ValueFrom[[]string](environment, "${app.servers}"))
+
+		var short struct {
+			Servers [2]string
+		}
+		require.Panics(t, func() { env.ConfigurationPropertiesFrom(environment, "app", &short) })
+	})
+
+	t.Run("should keep commas of list elements and limit gaps between indexes", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args("--my.servers[0]=a,b", "--my.servers[1]=c", "--my.ports[0]=80", "--my.ports[5]=443", "--my.huge[50000000]=a").
+			Environ().
+			Build()
+
+		var my struct {
+			Servers []string
+			Ports   []int
+			Huge    []string
+		}
+
+		require.Equal(t, []string{"a,b", "c"}, env.ValueFrom[[]string](environment, "${my.servers}"))
+		require.Equal(t, [2]string{"a,b", "c"}, env.ValueFrom[[2]string](environment, "${my.servers}"))
+		require.Equal(t, []int{80, 0, 0, 0, 0, 443}, env.ValueFrom[[]int](environment, "${my.ports}"))
+		require.Equal(t, "a,b,c", env.ValueFrom[string](environment, "${my.servers}"))
+
+		e := catch(func() { env.ConfigurationPropertiesFrom(environment, "my", &my) })
+		require.ErrorContains(t, e, "Cannot bind my.huge[50000000], 50000000 elements before it are not defined, at most 1000 may be missing")
+		require.Equal(t, []string{"a,b", "c"}, my.Servers)
+	})
+
+	t.Run("should bind list elements of environment variables with and without trailing underscore", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args().
+			Environ("MY_SERVICE_0_OTHER=first", "MY_SERVICE_1_OTHER=second", "MY_SERVERS_0=a", "MY_SERVERS_1_=b", "MY_MATRIX_0_1=x").
+			Build()
+
+		var my struct {
+			Service []struct{ Other string }
+			Servers []string
+			Matrix  [][]string
+		}
+		env.ConfigurationPropertiesFrom(environment, "my", &my)
+
+		require.Len(t, my.Service, 2)
+		require.Equal(t, "first", my.Service[0].Other)
+		require.Equal(t, "second", my.Service[1].Other)
+		require.Equal(t, []string{"a", "b"}, my.Servers)
+		require.Equal(t, [][]string{{"", "x"}}, my.Matrix)
+		require.Equal(t, "first", environment.Property("my.service[0].other"))
+		require.Equal(t, "a", environment.Property("my.servers[0]"))
+	})
+
+	t.Run("should bind maps from prefixed keys", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args("--app.datasources.primary.pool-size=20").
+			Environ("APP_DATASOURCES_REPLICA_URL=jdbc:replica", "APP_LIMITS_UPLOAD=10").
+			Build().
+			WithPropertySource(env.NewPropertiesPropertySource("application.properties", `
+app.datasources.primary.url=jdbc:primary
+app.datasources.primary.pool-size=10
+app.headers[X-Request-Id]=abc
+app.headers[x.forwarded.for]=proxy
+app.headers.accept=json
+app.limits.download=5
+`))
+
+		type Datasource struct {
+			URL      string
+			PoolSize int
+		}
+		var config struct {
+			Datasources map[string]Datasource
+			Headers     map[string]string
+			Limits      map[string]int
+			Labels      map[string]string
+		}
+		config.Limits = map[string]int{"download": 1, "default": 3}
+
+		env.ConfigurationPropertiesFrom(environment, "app", &config)
+
+		require.Equal(t, map[string]Datasource{
+			"primary": {URL: "jdbc:primary", PoolSize: 20},
+			"replica": {URL: "jdbc:replica"}}, config.Datasources)
+		require.Equal(t, map[string]string{"X-Request-Id": "abc", "x.forwarded.for": "proxy", "accept": "json"}, config.Headers)
+		require.Equal(t, map[string]int{"download": 5, "upload": 10, "default": 3}, config.Limits)
+		require.Nil(t, config.Labels)
+		require.Equal(t, map[string]string{"X-Request-Id": "abc", "x.forwarded.for": "proxy", "accept": "json"},
+			env.ValueFrom[map[string]string](environment, "${app.headers}"))
+		require.Equal(t, map[string]int{"a": 1}, env.ValueFrom[map[string]int](environment, "###{ {'a': 1} }###"))
+		require.Panics(t, func() { env.ValueFrom[map[string]string](environment, "${app.labels}") })
+	})
+
+	t.Run("should bind durations, data sizes and standard library types", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args().
+			Build().
+			WithPropertySource(env.NewYamlPropertySource("application.yaml", `
+server:
+  timeout: 1m30s
+  max-body-size: 10MB
+  base-url: https://example.com/api
+  trusted: [10.0.0.0/8, 192.168.0.0/16]
+  pattern: ^/api/.*$
+`))
+
+		var server struct {
+			Timeout     time.Duration
+			MaxBodySize int64
+			BaseURL     *url.URL
+			Trusted     []netip.Prefix
+			Pattern     *regexp.Regexp
+		}
+
+		env.ConfigurationPropertiesFrom(environment, "server", &server)
+
+		require.Equal(t, 90*time.Second, server.Timeout)
+		require.Equal(t, int64(10*1024*1024), server.MaxBodySize)
+		require.Equal(t, "example.com", server.BaseURL.Host)
+		require.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.168.0.0/16")}, server.Trusted)
+		require.True(t, server.Pattern.MatchString("/api/users"))
+		require.Equal(t, 5*time.Second, env.ValueFrom[time.Duration](environment, "${server.connect-timeout:5s}"))
+	})
+
+	t.Run("should bind keys of config tags and defaults", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args("--cache.ttl=1m").
+			Environ().
+			KeyTags("json", "yaml").
+			Build().
+			WithPropertySource(env.NewYamlPropertySource("application.yaml", `
+app:
+  address: localhost:6379
+  db-name: main
+  pool-size: 10
+  secret: hidden
+  internal: true
+  timeouts:
+    read: 5s
+`))
+
+		type Timeouts struct {
+			Read  time.Duration `json:"read"`
+			Write time.Duration `default:"${cache.ttl}"`
+		}
+		var config struct {
+			Addr     string        `config:"address"`
+			DBName   string        `json:"db-name,omitempty"`
+			PoolSize int           `yaml:"pool-size" json:"-" config:"pool-size"`
+			Secret   string        `config:"-"`
+			Internal bool          `json:"-"`
+			Idle     time.Duration `default:"30s" validate:"required"`
+			Retries  *int          `default:"3"`
+			Prefill  int           `default:"1"`
+			Missing  *Timeouts     `json:"missing"`
+			Timeouts `yaml:"timeouts"`
+		}
+		config.Prefill = 5
+
+		env.ConfigurationPropertiesFrom(environment, "app", &config)
+
+		require.Equal(t, "localhost:6379", config.Addr)
+		require.Equal(t, "main", config.DBName)
+		require.Equal(t, 10, config.PoolSize)
+		require.Empty(t, config.Secret)
+		require.False(t, config.Internal)
+		require.Equal(t, 30*time.Second, config.Idle)
+		require.Equal(t, 3, *config.Retries)
+		require.Equal(t, 5, config.Prefill)
+		require.Nil(t, config.Missing)
+		require.Equal(t, Timeouts{Read: 5 * time.Second, Write: time.Minute}, config.Timeouts)
+
+		// json and yaml tags are ignored unless enabled
+		var plain struct {
+			DBName string `json:"database"`
+		}
+		env.ConfigurationPropertiesFrom(env.NewBuilder().Args("--app.database=main", "--app.dbname=other").Environ().Build(), "app", &plain)
+		require.Equal(t, "other", plain.DBName)
+
+		var invalid struct {
+			Idle time.Duration `default:"soon"`
+		}
+		_, e := env.ConfigurationPropertiesEFrom(environment, "app", &invalid)
+		var conversion *env.ConversionError
+		require.True(t, errors.As(e, &conversion))
+		require.Equal(t, "app.idle", conversion.Key)
+		require.Equal(t, "default tag", conversion.Source)
+	})
+}
+
+type Timeouts struct {
+	Timeout int
 }
 
 func Test_Env_BindProperties(t *testing.T) {
@@ -108,6 +470,87 @@ func Test_Env_BindProperties(t *testing.T) {
 	})
 }
 
+func Test_Env_RegisterConverter(t *testing.T) {
+	t.Run("should convert with registered converter and unmarshaler methods", func(t *testing.T) {
+		env.RegisterConverter(func(value string) (Currency, error) {
+			if len(value) != 3 {
+				return Currency{}, fmt.Errorf("not a currency code: %s", value)
+			}
+			return Currency{Code: strings.ToUpper(value)}, nil
+		})
+		environment := env.NewBuilder().
+			Args().
+			Build().
+			WithPropertySource(env.MapPropertySourceOfMap("properties", map[string]string{
+				"shop.currency":        "eur",
+				"shop.accepted":        "usd, gbp",
+				"shop.rates.chf":       "chf",
+				"shop.log-level":       "WARN",
+				"shop.tier":            "gold",
+				"shop.mirrors":         "a;b",
+				"shop.invalid":         "euro",
+				"shop.invalid-level":   "LOUD",
+				"shop.fallback-level":  "${shop.log-level}",
+				"shop.tiers.preferred": "\"silver\"",
+			}))
+
+		var shop struct {
+			Currency      Currency
+			Accepted      []Currency
+			Rates         map[string]*Currency
+			LogLevel      slog.Level
+			Tier          Tier
+			Mirrors       Mirrors
+			FallbackLevel *slog.Level `value:"${shop.fallback-level}"`
+			Tiers         map[string]Tier
+		}
+
+		env.ConfigurationPropertiesFrom(environment, "shop", &shop)
+		env.BindPropertiesFrom(environment, &shop)
+
+		require.Equal(t, Currency{Code: "EUR"}, shop.Currency)
+		require.Equal(t, []Currency{{Code: "USD"}, {Code: "GBP"}}, shop.Accepted)
+		require.Equal(t, map[string]*Currency{"chf": {Code: "CHF"}}, shop.Rates)
+		require.Equal(t, slog.LevelWarn, shop.LogLevel)
+		require.Equal(t, Tier(3), shop.Tier)
+		require.Equal(t, Mirrors{"a", "b"}, shop.Mirrors)
+		require.Equal(t, slog.LevelWarn, *shop.FallbackLevel)
+		require.Equal(t, map[string]Tier{"preferred": 2}, shop.Tiers)
+		require.Equal(t, Currency{Code: "JPY"}, env.ValueFrom[Currency](environment, "${shop.yen:jpy}"))
+
+		require.Panics(t, func() { env.ValueFrom[Currency](environment, "${shop.invalid}") })
+		require.Panics(t, func() { env.ValueFrom[slog.Level](environment, "${shop.invalid-level}") })
+	})
+}
+
+type Currency struct {
+	Code string
+}
+
+// json.Unmarshaler
+type Tier int
+
+func (this *Tier) UnmarshalJSON(data []byte) error {
+	var name string
+	if e := json.Unmarshal(data, &name); e != nil {
+		return e
+	}
+	*this = map[string]Tier{"bronze": 1, "silver": 2, "gold": 3}[name]
+	return nil
+}
+
+// flag.Value
+type Mirrors []string
+
+func (this *Mirrors) String() string {
+	return strings.Join(*this, ";")
+}
+
+func (this *Mirrors) Set(value string) error {
+	*this = strings.Split(value, ";")
+	return nil
+}
+
 func Test_Env_MatchesProfiles(t *testing.T) {
 	t.Run("should match profiles properly", func(t *testing.T) {
 		env.SetActiveProfiles("test,hsqldb")
@@ -128,3 +571,144 @@ func Test_Env_MatchesProfiles(t *testing.T) {
 		require.True(t, env.MatchesProfiles("prod", "hsqldb"))
 	})
 }
+
+func Test_Env_Lookup(t *testing.T) {
+	t.Run("should return typed errors instead of panicking", func(t *testing.T) {
+		env.SetActiveProfiles("").
+			WithPropertySource(env.MapPropertySourceOfMap("properties", map[string]string{
+				"port":    "8080",
+				"host":    "localhost",
+				"expr":    "#{1 +}",
+				"encoded": "base64:%%%",
+			})).
+			WithPropertySource(env.NewBase64PropertySource())
+
+		port, e := env.Lookup[int]("port")
+		require.NoError(t, e)
+		require.Equal(t, 8080, port)
+
+		_, e = env.Lookup[int]("missing")
+		var notFound *env.PropertyNotFoundError
+		require.ErrorAs(t, e, &notFound)
+		require.Equal(t, "missing", notFound.Key)
+		require.Equal(t, "No value present for missing", e.Error())
+
+		_, e = env.Lookup[int]("host")
+		var conversion *env.ConversionError
+		require.ErrorAs(t, e, &conversion)
+		require.Equal(t, "host", conversion.Key)
+		require.Equal(t, "properties", conversion.Source)
+		require.Equal(t, "localhost", conversion.Value)
+		require.NotNil(t, errors.Unwrap(e))
+
+		_, e = env.Lookup[string]("expr")
+		var expression *env.ExpressionError
+		require.ErrorAs(t, e, &expression)
+		require.Equal(t, "expr", expression.Key)
+		require.Equal(t, "properties", expression.Source)
+		require.Equal(t, "1 +", expression.Expression)
+
+		_, e = env.Lookup[string]("encoded")
+		require.ErrorAs(t, e, &conversion)
+		require.Equal(t, "encoded", conversion.Key)
+		require.Equal(t, "properties", conversion.Source)
+	})
+}
+
+func Test_Env_ValueE(t *testing.T) {
+	t.Run("should return typed errors instead of panicking", func(t *testing.T) {
+		env.SetActiveProfiles("").
+			WithPropertySource(env.MapPropertySourceOfMap("properties", map[string]string{
+				"port": "8080"}))
+
+		port, e := env.ValueE[int]("${port}")
+		require.NoError(t, e)
+		require.Equal(t, 8080, port)
+
+		_, e = env.ValueE[int]("${missing}")
+		require.ErrorAs(t, e, new(*env.PropertyNotFoundError))
+
+		_, e = env.ValueE[bool]("${port}")
+		require.ErrorAs(t, e, new(*env.ConversionError))
+
+		_, e = env.ValueE[int]("#{${port} +}")
+		require.ErrorAs(t, e, new(*env.ExpressionError))
+
+		port, e = env.ValueE[int]("${missing:80}")
+		require.NoError(t, e)
+		require.Equal(t, 80, port)
+	})
+}
+
+func Test_Env_ConfigurationPropertiesE(t *testing.T) {
+	t.Run("should return partially bound target and error", func(t *testing.T) {
+		env.SetActiveProfiles("").
+			WithPropertySource(env.MapPropertySourceOfMap("properties", map[string]string{
+				"db.host": "localhost",
+				"db.port": "port"}))
+
+		var db struct {
+			Host string
+			Port int
+		}
+
+		actual, e := env.ConfigurationPropertiesE("db", &db)
+
+		require.Same(t, &db, actual)
+		require.Equal(t, "localhost", db.Host)
+		var conversion *env.ConversionError
+		require.ErrorAs(t, e, &conversion)
+		require.Equal(t, "db.port", conversion.Key)
+		require.Equal(t, "properties", conversion.Source)
+	})
+}
+
+func Test_Env_BindPropertiesE(t *testing.T) {
+	t.Run("should return error naming the field", func(t *testing.T) {
+		env.SetActiveProfiles("")
+
+		var db struct {
+			Host string `value:"${db.host}"`
+		}
+
+		actual, e := env.BindPropertiesE(&db)
+
+		require.Same(t, &db, actual)
+		require.ErrorContains(t, e, "Host")
+		var notFound *env.PropertyNotFoundError
+		require.ErrorAs(t, e, &notFound)
+		require.Equal(t, "db.host", notFound.Key)
+	})
+
+	t.Run("should return errors of the given environment", func(t *testing.T) {
+		environment := env.NewBuilder().
+			Args("--db.host=localhost", "--db.port=port").
+			Environ().
+			Build()
+
+		host, e := env.LookupFrom[string](environment, "db.host")
+		require.NoError(t, e)
+		require.Equal(t, "localhost", host)
+
+		_, e = env.LookupFrom[int](environment, "db.port")
+		require.ErrorAs(t, e, new(*env.ConversionError))
+
+		_, e = env.ValueEFrom[int](environment, "${db.missing}")
+		require.ErrorAs(t, e, new(*env.PropertyNotFoundError))
+
+		var db struct {
+			Host string
+			Port int
+		}
+		_, e = env.ConfigurationPropertiesEFrom(environment, "db", &db)
+		require.ErrorAs(t, e, new(*env.ConversionError))
+		require.Equal(t, "localhost", db.Host)
+
+		var tagged struct {
+			Port int `value:"${db.port}"`
+		}
+		_, e = env.BindPropertiesEFrom(environment, &tagged)
+		require.ErrorContains(t, e, "Port")
+		require.ErrorAs(t, e, new(*env.ConversionError))
+	})
+}
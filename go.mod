@@ -3,6 +3,7 @@ module github.com/go-external-config/go
 go 1.26.2
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/expr-lang/expr v1.17.8
 	github.com/go-errr/go v1.0.13
 	github.com/go-jang/go v1.0.1
@@ -0,0 +1,153 @@
+package str
+
+import (
+	"encoding"
+	"encoding/json"
+	"flag"
+	"io/fs"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jang/go/lang"
+)
+
+var parsersMu sync.RWMutex
+
+// parsers of types that are not just their kind, value types like url.URL are parsed by the parser of the pointer
+var parsers = map[reflect.Type]func(string) (any, error){
+	lang.TypeOf[time.Duration](): parseDuration,
+	lang.TypeOf[time.Time]():     parseTime,
+	lang.TypeOf[fs.FileMode](): func(value string) (any, error) {
+		mode, e := strconv.ParseUint(value, 8, 32)
+		return fs.FileMode(mode), e
+	},
+	lang.TypeOf[*url.URL](): func(value string) (any, error) {
+		return url.Parse(value)
+	},
+	lang.TypeOf[net.IP](): func(value string) (any, error) {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return ip, &net.ParseError{Type: "IP address", Text: value}
+		}
+		return ip, nil
+	},
+	lang.TypeOf[*net.IPNet](): func(value string) (any, error) {
+		_, network, e := net.ParseCIDR(value)
+		return network, e
+	},
+	lang.TypeOf[netip.Addr](): func(value string) (any, error) {
+		return netip.ParseAddr(value)
+	},
+	lang.TypeOf[netip.AddrPort](): func(value string) (any, error) {
+		return netip.ParseAddrPort(value)
+	},
+	lang.TypeOf[netip.Prefix](): func(value string) (any, error) {
+		return netip.ParsePrefix(value)
+	},
+	lang.TypeOf[*regexp.Regexp](): func(value string) (any, error) {
+		return regexp.Compile(value)
+	},
+	lang.TypeOf[*big.Int](): func(value string) (any, error) {
+		number, ok := new(big.Int).SetString(value, 0)
+		return number, lang.If(ok, nil, strconv.ErrSyntax)
+	},
+	lang.TypeOf[*big.Float](): func(value string) (any, error) {
+		number, _, e := big.ParseFloat(value, 10, 0, big.ToNearestEven)
+		return number, e
+	},
+}
+
+// Parser to use for values of the given type instead of the built-in one, parser must return value of that type.
+// Registered for pointer type, it is used for the value type as well
+//
+//	str.RegisterParser(lang.TypeOf[Currency](), func(value string) (any, error) {
+//		return CurrencyOf(value)
+//	})
+func RegisterParser(t reflect.Type, parser func(string) (any, error)) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[t] = parser
+}
+
+func parserOf(t reflect.Type) (func(string) (any, error), bool) {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	parser, ok := parsers[t]
+	return parser, ok
+}
+
+// value of type t parsed by UnmarshalText, UnmarshalJSON or Set method of the type, ok is false if it has none
+func unmarshal(value string, t reflect.Type) (result any, ok bool, e error) {
+	pointer := reflect.New(t)
+	target := pointer.Interface()
+	if t.Kind() == reflect.Pointer {
+		pointer.Elem().Set(reflect.New(t.Elem()))
+		target = pointer.Elem().Interface()
+	}
+	switch v := target.(type) {
+	case encoding.TextUnmarshaler:
+		e = v.UnmarshalText([]byte(value))
+	case json.Unmarshaler:
+		// plain text is taken as JSON string, INFO as "INFO"
+		data := []byte(value)
+		if !json.Valid(data) {
+			data, _ = json.Marshal(value)
+		}
+		e = v.UnmarshalJSON(data)
+	case flag.Value:
+		e = v.Set(value)
+	default:
+		return nil, false, nil
+	}
+	return pointer.Elem().Interface(), true, e
+}
+
+// 1h30m, plain number is nanoseconds
+func parseDuration(value string) (any, error) {
+	duration, e := time.ParseDuration(value)
+	if e != nil {
+		if nanoseconds, e2 := strconv.ParseInt(value, 10, 64); e2 == nil {
+			return time.Duration(nanoseconds), nil
+		}
+	}
+	return duration, e
+}
+
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", time.DateTime, time.DateOnly, time.TimeOnly}
+
+// RFC 3339, date-time, date or time of day without offset is local, time of day is on January 1 of year 0
+func parseTime(value string) (any, error) {
+	var e error
+	for _, layout := range timeLayouts {
+		var parsed time.Time
+		if parsed, e = time.ParseInLocation(layout, value, time.Local); e == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, e
+}
+
+var dataSizePattern = regexp.MustCompile(`(?i)^\s*(\d+(?:\.\d+)?)\s*(?:([KMGTP])i?)?B\s*$`)
+
+// 512KiB as 524288, units are powers of 1024 both for KB and KiB, same as size.KB of expressions
+func parseDataSize(value string) (string, bool) {
+	match := dataSizePattern.FindStringSubmatch(value)
+	if match == nil {
+		return value, false
+	}
+	size, _ := new(big.Rat).SetString(match[1])
+	exponent := strings.Index("_KMGTP", strings.ToUpper(match[2]))
+	size.Mul(size, new(big.Rat).SetInt(new(big.Int).Lsh(big.NewInt(1), uint(10*exponent))))
+	if !size.IsInt() {
+		return value, false
+	}
+	return size.Num().String(), true
+}
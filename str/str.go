@@ -18,7 +18,30 @@ func Parse[T any](value string) T {
 
 func ParseOfType(value string, t reflect.Type) any {
 	errMsg := "Failed to parse '%s' as %s\nCaused by: %s"
+	if parser, ok := parserOf(t); ok {
+		v, e := parser(value)
+		if e != nil {
+			panic(err.NewIllegalArgumentException(fmt.Sprintf(errMsg, value, t, e)))
+		}
+		return reflect.ValueOf(v).Convert(t).Interface()
+	}
+	if _, ok := parserOf(reflect.PointerTo(t)); ok {
+		return reflect.ValueOf(ParseOfType(value, reflect.PointerTo(t))).Elem().Interface()
+	}
+	if v, ok, e := unmarshal(value, t); ok {
+		if e != nil {
+			panic(err.NewIllegalArgumentException(fmt.Sprintf(errMsg, value, t, e)))
+		}
+		return v
+	}
+	if size, ok := parseDataSize(value); ok && t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64 {
+		value = size
+	}
 	switch t.Kind() {
+	case reflect.Pointer:
+		pointer := reflect.New(t.Elem())
+		pointer.Elem().Set(reflect.ValueOf(ParseOfType(value, t.Elem())))
+		return pointer.Interface()
 	case reflect.Int:
 		v, e := strconv.Atoi(value)
 		if e != nil {
@@ -99,11 +122,43 @@ func ParseOfType(value string, t reflect.Type) any {
 		return reflect.ValueOf(v).Convert(t).Interface()
 	case reflect.String:
 		return reflect.ValueOf(value).Convert(t).Interface()
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// []byte is the raw value, not a list of numbers
+			return reflect.ValueOf([]byte(value)).Convert(t).Interface()
+		}
+		items := SplitList(value)
+		var list reflect.Value
+		if t.Kind() == reflect.Slice {
+			list = reflect.MakeSlice(t, len(items), len(items))
+		} else if len(items) > t.Len() {
+			panic(err.NewIllegalArgumentException(fmt.Sprintf("Failed to parse '%s' as %s\nCaused by: %d elements do not fit", value, t, len(items))))
+		} else {
+			list = reflect.New(t).Elem()
+		}
+		for i, item := range items {
+			list.Index(i).Set(reflect.ValueOf(ParseOfType(item, t.Elem())))
+		}
+		return list.Interface()
 	default:
 		panic(err.NewIllegalArgumentException(fmt.Sprintf("Unsupported type: %s", t)))
 	}
 }
 
+// Comma-separated values with surrounding spaces trimmed, empty for blank value
+//
+//	str.SplitList("a, b,c") // [a b c]
+func SplitList(value string) []string {
+	if len(strings.TrimSpace(value)) == 0 {
+		return nil
+	}
+	items := strings.Split(value, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
+
 func ReplaceChars(str string, rules map[rune]rune) string {
 	var builder strings.Builder
 	builder.Grow(len(str))
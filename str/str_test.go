@@ -1,7 +1,14 @@
 package str_test
 
 import (
+	"io/fs"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/go-external-config/go/str"
 	"github.com/stretchr/testify/require"
@@ -28,6 +35,43 @@ func Test_Parse(t *testing.T) {
 		require.Equal(t, Port(123), str.Parse[Port](value))
 		require.Equal(t, true, str.Parse[bool]("true"))
 	})
+
+	t.Run("parse comma-separated string as slice or array", func(t *testing.T) {
+		require.Equal(t, []int{1, 2, 3}, str.Parse[[]int]("1, 2,3"))
+		require.Equal(t, [3]string{"a", "b"}, str.Parse[[3]string]("a,b"))
+		require.Empty(t, str.Parse[[]string](" "))
+		require.Equal(t, []byte("a,b"), str.Parse[[]byte]("a,b"))
+		require.Panics(t, func() { str.Parse[[1]string]("a,b") })
+	})
+
+	t.Run("parse durations, data sizes and standard library types", func(t *testing.T) {
+		require.Equal(t, 90*time.Minute, str.Parse[time.Duration]("1h30m"))
+		require.Equal(t, time.Duration(30), str.Parse[time.Duration]("30"))
+		require.Equal(t, []time.Duration{time.Second, time.Millisecond}, str.Parse[[]time.Duration]("1s, 1ms"))
+		require.Equal(t, int64(10*1024*1024), str.Parse[int64]("10MB"))
+		require.Equal(t, 512*1024, str.Parse[int]("512KiB"))
+		require.Equal(t, uint64(1536), str.Parse[uint64]("1.5 kb"))
+		require.Equal(t, int32(7), str.Parse[int32]("7B"))
+		require.Panics(t, func() { str.Parse[int8]("1KB") })
+		require.Panics(t, func() { str.Parse[int]("0.5B") })
+		require.True(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Equal(str.Parse[time.Time]("2024-01-02T03:04:05Z")))
+		require.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local), str.Parse[time.Time]("2024-01-02"))
+		require.Equal(t, "https://example.com/path?q=1", str.Parse[*url.URL]("https://example.com/path?q=1").String())
+		require.Equal(t, "example.com", str.Parse[url.URL]("https://example.com").Host)
+		require.Equal(t, net.ParseIP("10.0.0.1"), str.Parse[net.IP]("10.0.0.1"))
+		require.Panics(t, func() { str.Parse[net.IP]("10.0.0") })
+		require.Equal(t, "10.0.0.0/8", str.Parse[*net.IPNet]("10.0.0.0/8").String())
+		require.Equal(t, netip.MustParseAddr("::1"), str.Parse[netip.Addr]("::1"))
+		require.Equal(t, netip.MustParseAddrPort("127.0.0.1:8080"), str.Parse[netip.AddrPort]("127.0.0.1:8080"))
+		require.Equal(t, netip.MustParsePrefix("192.168.0.0/16"), str.Parse[netip.Prefix]("192.168.0.0/16"))
+		require.True(t, str.Parse[*regexp.Regexp]("^a+$").MatchString("aaa"))
+		require.Panics(t, func() { str.Parse[*regexp.Regexp]("(") })
+		require.Equal(t, "123456789012345678901234567890", str.Parse[*big.Int]("123456789012345678901234567890").String())
+		require.Equal(t, *big.NewInt(255), str.Parse[big.Int]("0xff"))
+		require.Equal(t, "1.5", str.Parse[*big.Float]("1.5").String())
+		require.Equal(t, fs.FileMode(0o644), str.Parse[fs.FileMode]("0644"))
+		require.Equal(t, 8, *str.Parse[*int]("8"))
+	})
 }
 
 func Test_ReplaceChars(t *testing.T) {